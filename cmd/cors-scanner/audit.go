@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// auditEntry is one line of the --audit-log file.
+type auditEntry struct {
+	Time   time.Time `json:"time"`
+	Target string    `json:"target"`
+	Method string    `json:"method"`
+	Origin string    `json:"origin,omitempty"`
+	Status int       `json:"status,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+var (
+	auditFile *os.File
+	auditOnce sync.Once
+	auditMux  sync.Mutex
+)
+
+// auditTransport records every request that actually goes out, redirects
+// included, as a JSON line in the --audit-log file. Entries are only ever
+// appended, so one file can cover several runs of an engagement.
+type auditTransport struct {
+	next http.RoundTripper
+}
+
+func (t *auditTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	entry := auditEntry{
+		Time:   time.Now().UTC(),
+		Target: req.URL.String(),
+		Method: req.Method,
+		Origin: req.Header.Get("Origin"),
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		entry.Error = err.Error()
+	} else {
+		entry.Status = resp.StatusCode
+	}
+	writeAudit(entry)
+	return resp, err
+}
+
+func writeAudit(entry auditEntry) {
+	auditOnce.Do(func() {
+		var err error
+		auditFile, err = os.OpenFile(config.AuditLog, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			log.Printf("Error opening audit log: %v", err)
+		}
+	})
+	if auditFile == nil {
+		return
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	auditMux.Lock()
+	defer auditMux.Unlock()
+	if _, err := auditFile.Write(append(line, '\n')); err != nil {
+		log.Printf("Error writing audit log: %v", err)
+	}
+}
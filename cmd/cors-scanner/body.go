@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// maxBodySize bounds how much of a response body is ever inspected.
+const maxBodySize = 5 << 20
+
+// readBody returns the decoded response body. Because makeRequest sets
+// Accept-Encoding itself, net/http does not decompress transparently and
+// gzip, deflate and br have to be handled here.
+func readBody(resp *http.Response) ([]byte, error) {
+	var reader io.Reader = resp.Body
+
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		reader = gz
+	case "deflate":
+		fl, err := deflateReader(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer fl.Close()
+		reader = fl
+	case "br":
+		reader = brotli.NewReader(resp.Body)
+	}
+
+	return io.ReadAll(io.LimitReader(reader, maxBodySize))
+}
+
+// deflateReader decodes a deflate body, which RFC 9110 defines as zlib
+// wrapped. Some servers send raw deflate instead, recognisable by the
+// missing zlib header.
+func deflateReader(body io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(body)
+	header, err := buffered.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
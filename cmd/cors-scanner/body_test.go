@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestReadBody(t *testing.T) {
+	const body = `{"cors":"probe"}`
+	compress := func(newWriter func(io.Writer) io.WriteCloser) []byte {
+		var b bytes.Buffer
+		w := newWriter(&b)
+		w.Write([]byte(body))
+		w.Close()
+		return b.Bytes()
+	}
+
+	tests := []struct {
+		name     string
+		encoding string
+		data     []byte
+	}{
+		{"identity", "", []byte(body)},
+		{"gzip", "gzip", compress(func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })},
+		{"zlib deflate", "deflate", compress(func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) })},
+		{"raw deflate", "deflate", compress(func(w io.Writer) io.WriteCloser {
+			fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+			return fw
+		})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				Header: http.Header{"Content-Encoding": {tt.encoding}},
+				Body:   io.NopCloser(bytes.NewReader(tt.data)),
+			}
+			got, err := readBody(resp)
+			if err != nil {
+				t.Fatalf("readBody: %v", err)
+			}
+			if string(got) != body {
+				t.Errorf("readBody = %q, want %q", got, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CacheInfo records the caching headers of a response. A cache that
+// stores a reflected Access-Control-Allow-Origin without keying it on the
+// Origin serves it to every later visitor.
+type CacheInfo struct {
+	CacheControl string `json:"cache_control,omitempty"`
+	Vary         string `json:"vary,omitempty"`
+}
+
+// cacheInfo extracts the caching headers of a response, or nil when it
+// has none.
+func cacheInfo(resp *http.Response) *CacheInfo {
+	info := &CacheInfo{
+		CacheControl: strings.Join(resp.Header.Values("Cache-Control"), ", "),
+		Vary:         strings.Join(resp.Header.Values("Vary"), ", "),
+	}
+	if info.CacheControl == "" && info.Vary == "" {
+		return nil
+	}
+	return info
+}
+
+// Cacheable reports whether Cache-Control lets a shared cache store the
+// response: public, or a positive max-age or s-maxage, and none of
+// no-store, no-cache and private.
+func (c *CacheInfo) Cacheable() bool {
+	if c == nil {
+		return false
+	}
+	cacheable := false
+	for _, directive := range strings.Split(strings.ToLower(c.CacheControl), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch name {
+		case "no-store", "no-cache", "private":
+			return false
+		case "public":
+			cacheable = true
+		case "max-age", "s-maxage":
+			if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && seconds > 0 {
+				cacheable = true
+			}
+		}
+	}
+	return cacheable
+}
+
+// VariesOnOrigin reports whether Vary keys the response on the Origin
+// header. Vary: * keeps it out of caches altogether.
+func (c *CacheInfo) VariesOnOrigin() bool {
+	if c == nil {
+		return false
+	}
+	for _, name := range strings.Split(c.Vary, ",") {
+		if name = strings.TrimSpace(name); strings.EqualFold(name, "Origin") || name == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// cachePoisoningRisk flags a reflected origin in a cacheable response
+// without Vary: Origin: a cache may store the attacker's ACAO, or serve
+// the victim's origin a response allowing another.
+func cachePoisoningRisk(result ScanResult) []Risk {
+	reflected := result.Test != "existing" && result.Origin != "" && result.Headers.ACAO == result.Origin
+	if !reflected || !result.Cache.Cacheable() || result.Cache.VariesOnOrigin() {
+		return nil
+	}
+	return []Risk{{ID: "missing-vary-origin", Severity: SeverityMedium,
+		Message: "Reflected origin in a cacheable response without Vary: Origin - CORS cache poisoning candidate (Cache-Control: " + result.Cache.CacheControl + ")"}}
+}
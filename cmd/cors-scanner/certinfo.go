@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// certExpiryWarning is how close to expiry a certificate is flagged as
+// expiring soon in reports.
+const certExpiryWarning = 30 * 24 * time.Hour
+
+// CertInfo describes the leaf certificate an HTTPS target presented. It
+// identifies the service behind shared IPs and shows stale staging certs.
+type CertInfo struct {
+	Subject  string    `json:"subject"`
+	Issuer   string    `json:"issuer"`
+	SANs     []string  `json:"sans,omitempty"`
+	NotAfter time.Time `json:"not_after"`
+	Error    string    `json:"error,omitempty"` // why verification failed, with --insecure
+}
+
+// certInfo returns the leaf certificate details of a response, or nil for
+// plain HTTP. Connections made with --tls-fingerprint carry no TLS state
+// in the response.
+func certInfo(resp *http.Response) *CertInfo {
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+	cert := resp.TLS.PeerCertificates[0]
+
+	info := &CertInfo{
+		Subject:  cert.Subject.String(),
+		Issuer:   cert.Issuer.String(),
+		SANs:     append([]string(nil), cert.DNSNames...),
+		NotAfter: cert.NotAfter.UTC(),
+	}
+	for _, ip := range cert.IPAddresses {
+		info.SANs = append(info.SANs, ip.String())
+	}
+	if config.Insecure {
+		info.Error = verifyCertificate(resp.TLS, resp.Request.URL.Hostname())
+	}
+	return info
+}
+
+// ExpiresSoon reports whether the certificate is expired or expires
+// within certExpiryWarning.
+func (c *CertInfo) ExpiresSoon() bool {
+	return c != nil && time.Until(c.NotAfter) < certExpiryWarning
+}
+
+// Summary renders the certificate on one line for reports.
+func (c *CertInfo) Summary() string {
+	s := c.Subject + " (issuer " + c.Issuer + ", expires " + c.NotAfter.Format("2006-01-02")
+	if c.ExpiresSoon() {
+		s += ", EXPIRING SOON"
+	}
+	if c.Error != "" {
+		s += ", UNTRUSTED: " + c.Error
+	}
+	s += ")"
+	if len(c.SANs) > 0 {
+		s += " SANs: " + strings.Join(c.SANs, ", ")
+	}
+	return s
+}
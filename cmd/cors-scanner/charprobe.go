@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"cors-scanner/pkg/corscan"
+)
+
+// probeChars are injected one at a time into a reflected origin. They
+// cover URL delimiters, characters browsers allow in hostnames and the
+// usual regex and parser confusables.
+var probeChars = []string{
+	"`", "!", "$", "&", "'", "(", ")", "*", "+", ",", ";", "=", "_", "-", "~",
+	"{", "}", "|", "%", "\"", "<", ">", "^", "@", "#", "?", "/", ":", "[", "]", "\\", " ",
+}
+
+// CharProbe maps which special characters injected into a reflected origin
+// come back in Access-Control-Allow-Origin. Reflected characters are echoed
+// verbatim, altered ones come back stripped or encoded and rejected ones
+// get no ACAO at all.
+type CharProbe struct {
+	Reflected []string `json:"reflected,omitempty"`
+	Altered   []string `json:"altered,omitempty"`
+	Rejected  []string `json:"rejected,omitempty"`
+}
+
+// Unsanitized reports whether every probed character was echoed back.
+func (p *CharProbe) Unsanitized() bool {
+	return p != nil && len(p.Altered) == 0 && len(p.Rejected) == 0 && len(p.Reflected) > 0
+}
+
+// probeReflectionChars re-sends a reflected origin once per probe
+// character, inserted just before the top-level domain.
+func probeReflectionChars(ctx context.Context, client *http.Client, method, targetURL, origin string) *CharProbe {
+	dot := strings.LastIndex(origin, ".")
+	if dot < 0 {
+		return nil
+	}
+
+	probe := &CharProbe{}
+	for _, c := range probeChars {
+		injected := origin[:dot] + c + origin[dot:]
+		resp, err := sendWithRetry(ctx, client, corscan.Request{Method: method, URL: targetURL, Origin: injected})
+		if err != nil {
+			continue
+		}
+		// Read the raw header: corscan.ParseHeaders splits on commas.
+		acao := resp.Header.Get("Access-Control-Allow-Origin")
+		resp.Body.Close()
+
+		switch {
+		case acao == injected:
+			probe.Reflected = append(probe.Reflected, c)
+		case acao == "" || acao == "null":
+			probe.Rejected = append(probe.Rejected, c)
+		default:
+			probe.Altered = append(probe.Altered, c)
+		}
+	}
+	return probe
+}
+
+func formatChars(chars []string) string {
+	if len(chars) == 0 {
+		return "none"
+	}
+	quoted := make([]string, len(chars))
+	for i, c := range chars {
+		quoted[i] = strconv.Quote(c)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// clusterBodySize bounds how much of each body goes into its signature.
+const clusterBodySize = 64 << 10
+
+// volatileHeaders differ between responses of the same backend and are
+// left out of signatures.
+var volatileHeaders = map[string]bool{
+	"Age": true, "Cf-Ray": true, "Date": true, "Etag": true, "Expires": true,
+	"Last-Modified": true, "Set-Cookie": true, "X-Amz-Cf-Id": true,
+	"X-Request-Id": true, "X-Amzn-Requestid": true, "X-Served-By": true,
+}
+
+var (
+	digitRuns      = regexp.MustCompile(`[0-9]+`)
+	whitespaceRuns = regexp.MustCompile(`\s+`)
+)
+
+// responseSignature hashes what identifies the software behind a
+// response: its status, header names and body, with the target's host
+// name, numbers and whitespace normalised away, so a shared backend,
+// wildcard vhost or parking page gives the same signature on every host.
+func responseSignature(resp *http.Response, targetURL string) string {
+	var names []string
+	for name := range resp.Header {
+		if !volatileHeaders[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	body, _ := readBody(&http.Response{Header: resp.Header, Body: io.NopCloser(io.LimitReader(resp.Body, clusterBodySize))})
+	text := string(body)
+	if parsed, err := url.Parse(targetURL); err == nil && parsed.Hostname() != "" {
+		text = strings.ReplaceAll(text, parsed.Hostname(), "{host}")
+	}
+	text = digitRuns.ReplaceAllString(text, "0")
+	text = whitespaceRuns.ReplaceAllString(text, " ")
+
+	sum := sha256.Sum256([]byte(strconv.Itoa(resp.StatusCode) + "\n" + strings.Join(names, ",") + "\n" + text))
+	return hex.EncodeToString(sum[:8])
+}
+
+// responseCluster is a set of URLs whose responses to every test match.
+type responseCluster struct {
+	ID       string
+	URLs     []string
+	Findings int
+}
+
+// urlClusterKey combines the signatures and CORS policies a URL returned
+// across all tests, with its origins and host name normalised away.
+func urlClusterKey(list []ScanResult) string {
+	var parts []string
+	for _, result := range list {
+		acao := result.Headers.ACAO
+		if result.Origin != "" {
+			acao = strings.ReplaceAll(acao, result.Origin, "{origin}")
+		}
+		if parsed, err := url.Parse(result.URL); err == nil && parsed.Hostname() != "" {
+			acao = strings.ReplaceAll(acao, parsed.Hostname(), "{host}")
+		}
+		parts = append(parts, strings.Join([]string{testName(result), methodOf(result), result.Vantage, acao, result.Headers.ACAC, result.Signature}, "|"))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "\n")
+}
+
+// clusterResults groups URLs whose responses are near-identical and
+// annotates their results with the cluster ID, so one misconfigured
+// gateway behind many host names reads as one finding, not hundreds.
+// Results without signatures are not clustered.
+func clusterResults(list []ScanResult) []*responseCluster {
+	byURL := make(map[string][]ScanResult)
+	var urls []string
+	for _, result := range list {
+		if result.Signature == "" {
+			continue
+		}
+		if byURL[result.URL] == nil {
+			urls = append(urls, result.URL)
+		}
+		byURL[result.URL] = append(byURL[result.URL], result)
+	}
+
+	byKey := make(map[string]*responseCluster)
+	var clusters []*responseCluster
+	for _, u := range urls {
+		key := urlClusterKey(byURL[u])
+		cluster := byKey[key]
+		if cluster == nil {
+			cluster = &responseCluster{}
+			byKey[key] = cluster
+			clusters = append(clusters, cluster)
+		}
+		cluster.URLs = append(cluster.URLs, u)
+		for _, result := range byURL[u] {
+			if len(assessRisks(result)) > 0 {
+				cluster.Findings++
+			}
+		}
+	}
+
+	var shared []*responseCluster
+	for _, cluster := range clusters {
+		if len(cluster.URLs) > 1 {
+			shared = append(shared, cluster)
+		}
+	}
+	sort.SliceStable(shared, func(i, j int) bool { return len(shared[i].URLs) > len(shared[j].URLs) })
+
+	ids := make(map[string]string)
+	for i, cluster := range shared {
+		cluster.ID = fmt.Sprintf("C%d", i+1)
+		for _, u := range cluster.URLs {
+			ids[u] = cluster.ID
+		}
+	}
+	for i := range list {
+		list[i].Cluster = ids[list[i].URL]
+	}
+	return shared
+}
+
+// clusterSizes counts the URLs of each cluster in a result list.
+func clusterSizes(list []ScanResult) map[string]int {
+	urls := make(map[string]map[string]bool)
+	for _, result := range list {
+		if result.Cluster == "" {
+			continue
+		}
+		if urls[result.Cluster] == nil {
+			urls[result.Cluster] = make(map[string]bool)
+		}
+		urls[result.Cluster][result.URL] = true
+	}
+	sizes := make(map[string]int)
+	for id, set := range urls {
+		sizes[id] = len(set)
+	}
+	return sizes
+}
+
+// clusterLabel describes a result's cluster for output and reports.
+func clusterLabel(result ScanResult, sizes map[string]int) string {
+	return fmt.Sprintf("%s (%d URLs with near-identical responses)", result.Cluster, sizes[result.Cluster])
+}
+
+func printClusters(clusters []*responseCluster) {
+	if len(clusters) == 0 {
+		return
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 70))
+	fmt.Println("RESPONSE CLUSTERS - URLs with near-identical responses")
+	fmt.Println(strings.Repeat("=", 70))
+	for _, cluster := range clusters {
+		examples := cluster.URLs
+		more := ""
+		if len(examples) > 3 {
+			more = fmt.Sprintf(" (+%d more)", len(examples)-3)
+			examples = examples[:3]
+		}
+		fmt.Printf("\n%s: %d URLs, %d findings between them\n", cluster.ID, len(cluster.URLs), cluster.Findings)
+		fmt.Printf("    %s%s\n", strings.Join(examples, ", "), more)
+	}
+	fmt.Println("\nFindings in one cluster most likely share one misconfiguration.")
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// loadConfigFile applies the settings of a --config YAML file. Its keys are
+// flag names without the dashes; lists set repeatable flags once per item.
+// Flags given on the command line win over the file.
+func loadConfigFile(cmd *cobra.Command) error {
+	if config.ConfigFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(config.ConfigFile)
+	if err != nil {
+		return fmt.Errorf("cannot read config file: %v", err)
+	}
+	var settings map[string]interface{}
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return fmt.Errorf("cannot parse config file %s: %v", config.ConfigFile, err)
+	}
+
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	flags := cmd.Flags()
+	for _, name := range names {
+		if name == "config" {
+			return fmt.Errorf("%s: config files cannot include other config files", config.ConfigFile)
+		}
+		if flags.Lookup(name) == nil {
+			// Scan settings are shared with subcommands that ignore them.
+			if cmd.Root().Flags().Lookup(name) != nil {
+				continue
+			}
+			return fmt.Errorf("%s: unknown setting %q", config.ConfigFile, name)
+		}
+		if flags.Changed(name) || settings[name] == nil {
+			continue
+		}
+
+		values, ok := settings[name].([]interface{})
+		if !ok {
+			values = []interface{}{settings[name]}
+		}
+		for _, value := range values {
+			if err := flags.Set(name, fmt.Sprint(value)); err != nil {
+				return fmt.Errorf("%s: invalid %s: %v", config.ConfigFile, name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"cors-scanner/pkg/corscan"
+)
+
+// Verification states set by --verify.
+const (
+	verifyConfirmed = "confirmed"
+	verifyPotential = "potential"
+)
+
+// confirmableClasses are the classes a credentialed cross-origin read can
+// exploit. Wildcards are included to be marked potential: browsers refuse
+// `*` for requests with credentials.
+var confirmableClasses = []string{
+	corscan.ClassWildcard, corscan.ClassNullTrust, corscan.ClassNullTrustCredentials, corscan.ClassReflection,
+	corscan.ClassSchemeDowngrade, corscan.ClassSubdomainWildcard, corscan.ClassSubdomainTrust,
+	corscan.ClassLocalTrust, corscan.ClassInternalTrust,
+}
+
+// printVerification summarizes the verdicts --verify gave the findings.
+func printVerification(list []ScanResult) {
+	confirmed, potential := 0, 0
+	for _, result := range list {
+		switch result.Verification {
+		case verifyConfirmed:
+			confirmed++
+		case verifyPotential:
+			potential++
+		}
+	}
+	if confirmed+potential > 0 {
+		fmt.Printf("[+] Verification: %d confirmed, %d potential.\n", confirmed, potential)
+	}
+}
+
+// confirmFinding re-sends the request behind a candidate finding with a
+// Cookie header, as a browser does for a withCredentials request, and
+// returns confirmed when the exact origin is still allowed together with
+// Access-Control-Allow-Credentials: true. Anything else is potential: many
+// servers reflect origins only on anonymous requests, or drop CORS headers
+// once a session is involved. A finding is left unverified when ctx ends
+// the scan first.
+func confirmFinding(ctx context.Context, result ScanResult) string {
+	req, err := newScanRequest(methodOf(result), result.URL, result.Origin)
+	if err != nil {
+		log.Printf("Error verifying %s: %v", result.URL, err)
+		emitError(requestErrorCode(err), result.URL, err)
+		return verifyPotential
+	}
+	for name, values := range replayHeader(result) {
+		req.Header[name] = values
+	}
+	// Without configured cookies a placeholder still makes the request
+	// credentialed.
+	if req.Header.Get("Cookie") == "" {
+		req.AddCookie(&http.Cookie{Name: "cors_scanner_verify", Value: "1"})
+	}
+
+	if err := waitToSend(ctx, result.URL); err != nil {
+		return ""
+	}
+	resp, err := buildHTTPClient(vantageProxy(result.Vantage)).Do(req.WithContext(ctx))
+	if err != nil {
+		if ctx.Err() != nil {
+			return ""
+		}
+		emitError(requestErrorCode(err), result.URL, err)
+		if config.Verbose {
+			fmt.Printf("Error verifying %s: %v\n", result.URL, err)
+		}
+		return verifyPotential
+	}
+	headers := corscan.ParseHeaders(resp.Header)
+	resp.Body.Close()
+
+	if headers.ACAO == result.Origin && strings.TrimSpace(headers.ACAC) == "true" {
+		return verifyConfirmed
+	}
+	return verifyPotential
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cookieJar holds the --cookie-jar cookies, nil without one. The jar does
+// the domain, path, secure and expiry matching for every request.
+var cookieJar http.CookieJar
+
+// fileCookie is a cookie as stored in a cookie file. Browser extensions
+// such as Cookie-Editor export expirationDate; HAR files use expires.
+type fileCookie struct {
+	Name           string  `json:"name"`
+	Value          string  `json:"value"`
+	Domain         string  `json:"domain"`
+	Path           string  `json:"path"`
+	Secure         bool    `json:"secure"`
+	HTTPOnly       bool    `json:"httpOnly"`
+	HostOnly       bool    `json:"hostOnly"`
+	ExpirationDate float64 `json:"expirationDate"`
+	Expires        string  `json:"expires"`
+}
+
+// harFile is the part of a HAR archive that carries cookies.
+type harFile struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				URL     string       `json:"url"`
+				Cookies []fileCookie `json:"cookies"`
+			} `json:"request"`
+			Response struct {
+				Cookies []fileCookie `json:"cookies"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// loadCookieJar reads a Netscape cookies.txt file (as written by curl,
+// wget and browser extensions), a JSON array of browser-exported cookies
+// or a HAR archive into cookieJar.
+func loadCookieJar(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read cookie jar: %v", err)
+	}
+
+	var cookies []fileCookie
+	switch trimmed := bytes.TrimSpace(data); {
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		err = json.Unmarshal(trimmed, &cookies)
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		cookies, err = readHARCookies(trimmed)
+	default:
+		cookies, err = readNetscapeCookies(data)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot parse cookie jar %s: %v", path, err)
+	}
+
+	jar, _ := cookiejar.New(nil)
+	for _, c := range cookies {
+		host := strings.TrimPrefix(c.Domain, ".")
+		if c.Name == "" || host == "" {
+			continue
+		}
+		scheme := "http"
+		if c.Secure {
+			scheme = "https"
+		}
+		cookie := &http.Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HTTPOnly,
+		}
+		if !c.HostOnly {
+			cookie.Domain = host
+		}
+		if c.ExpirationDate > 0 {
+			cookie.Expires = time.Unix(int64(c.ExpirationDate), 0)
+		} else if expires, err := time.Parse(time.RFC3339, c.Expires); err == nil {
+			cookie.Expires = expires
+		}
+		jar.SetCookies(&url.URL{Scheme: scheme, Host: host, Path: c.Path}, []*http.Cookie{cookie})
+	}
+	cookieJar = jar
+	return nil
+}
+
+// readNetscapeCookies parses the tab-separated cookies.txt format:
+// domain, include-subdomains, path, secure, expiry, name and value.
+func readNetscapeCookies(data []byte) ([]fileCookie, error) {
+	var cookies []fileCookie
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		httpOnly := false
+		if rest, ok := strings.CutPrefix(text, "#HttpOnly_"); ok {
+			text, httpOnly = rest, true
+		}
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) == 6 {
+			fields = append(fields, "") // empty value
+		}
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("line %d: expected 7 tab-separated fields", line)
+		}
+		expiry, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid expiry %q", line, fields[4])
+		}
+		cookies = append(cookies, fileCookie{
+			Domain:         fields[0],
+			HostOnly:       !strings.EqualFold(fields[1], "TRUE"),
+			Path:           fields[2],
+			Secure:         strings.EqualFold(fields[3], "TRUE"),
+			ExpirationDate: expiry,
+			Name:           fields[5],
+			Value:          fields[6],
+			HTTPOnly:       httpOnly,
+		})
+	}
+	return cookies, scanner.Err()
+}
+
+// readHARCookies collects the cookies of every request and response in
+// a HAR archive. HAR request cookies carry no domain, so they are scoped
+// to the host they were sent to.
+func readHARCookies(data []byte) ([]fileCookie, error) {
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, err
+	}
+
+	var cookies []fileCookie
+	for _, entry := range har.Log.Entries {
+		requestURL, err := url.Parse(entry.Request.URL)
+		if err != nil {
+			continue
+		}
+		for _, list := range [][]fileCookie{entry.Request.Cookies, entry.Response.Cookies} {
+			for _, c := range list {
+				if c.Domain == "" {
+					c.Domain, c.HostOnly = requestURL.Hostname(), true
+				}
+				if c.Path == "" {
+					c.Path = "/"
+				}
+				cookies = append(cookies, c)
+			}
+		}
+	}
+	return cookies, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"net/url"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+const netscapeCookies = "# Netscape HTTP Cookie File\n" +
+	".example.com\tTRUE\t/\tFALSE\t4102444800\ttheme\tdark\n" +
+	"api.example.com\tFALSE\t/v1\tTRUE\t4102444800\tsession\tabc123\n" +
+	"#HttpOnly_api.example.com\tFALSE\t/\tTRUE\t4102444800\tcsrf\ttok\n" +
+	"old.example.com\tFALSE\t/\tFALSE\t946684800\texpired\tyes\n"
+
+const jsonCookies = `[
+	{"name": "theme", "value": "dark", "domain": ".example.com", "path": "/"},
+	{"name": "session", "value": "abc123", "domain": "api.example.com", "path": "/v1", "secure": true, "hostOnly": true, "expirationDate": 4102444800}
+]`
+
+const harCookies = `{"log": {"entries": [
+	{"request": {"url": "https://api.example.com/v1/me", "cookies": [{"name": "session", "value": "abc123"}]},
+	 "response": {"cookies": [{"name": "theme", "value": "dark", "domain": ".example.com", "path": "/"}]}}
+]}}`
+
+func TestLoadCookieJar(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    map[string][]string // URL -> cookie names sent
+	}{
+		{"cookies.txt", "cookies.txt", netscapeCookies, map[string][]string{
+			"https://api.example.com/v1/me": {"csrf", "session", "theme"},
+			"http://api.example.com/v1/me":  {"theme"},
+			"https://www.example.com/":      {"theme"},
+			"https://old.example.com/":      {"theme"},
+			"https://example.org/":          nil,
+		}},
+		{"browser export", "cookies.json", jsonCookies, map[string][]string{
+			"https://api.example.com/v1/me":  {"session", "theme"},
+			"https://api.example.com/":       {"theme"},
+			"https://sub.api.example.com/v1": {"theme"},
+		}},
+		{"HAR", "session.har", harCookies, map[string][]string{
+			"https://api.example.com/v1/me": {"session", "theme"},
+			"https://www.example.com/":      {"theme"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() { cookieJar = nil }()
+			if err := loadCookieJar(writeTemp(t, tt.file, tt.content)); err != nil {
+				t.Fatalf("loadCookieJar: %v", err)
+			}
+			for rawURL, want := range tt.want {
+				target, _ := url.Parse(rawURL)
+				var got []string
+				for _, c := range cookieJar.Cookies(target) {
+					got = append(got, c.Name)
+				}
+				sort.Strings(got)
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("cookies for %s = %v, want %v", rawURL, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadCookieJarErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"too few fields", "example.com\tTRUE\t/\n"},
+		{"invalid expiry", "example.com\tTRUE\t/\tFALSE\tsoon\tname\tvalue\n"},
+		{"invalid JSON", `[{"name": "theme",}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() { cookieJar = nil }()
+			if err := loadCookieJar(writeTemp(t, "cookies.txt", tt.content)); err == nil {
+				t.Errorf("loadCookieJar succeeded, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+)
+
+// curlCommand returns a curl invocation that repeats the request behind a
+// result with the same origin, headers, cookies and proxy.
+func curlCommand(result ScanResult) string {
+	args := []string{"curl", "-s", "-D", "-", "-o", "/dev/null"}
+	if config.Insecure {
+		args = append(args, "-k")
+	}
+	if config.CACert != "" {
+		args = append(args, "--cacert", shellQuote(config.CACert))
+	}
+	if config.ClientCert != "" {
+		args = append(args, "--cert", shellQuote(config.ClientCert), "--key", shellQuote(config.ClientKey))
+	}
+	if method := methodOf(result); method != "GET" {
+		args = append(args, "-X", method)
+	}
+
+	proxy := config.Proxy
+	for _, v := range vantages {
+		if v.Name == result.Vantage {
+			proxy = v.Proxy
+		}
+	}
+	if proxy != "" {
+		args = append(args, "-x", shellQuote(proxy))
+		if config.ProxyAuth != "" && !strings.Contains(proxy, "@") {
+			args = append(args, "--proxy-user", shellQuote(config.ProxyAuth))
+		}
+	}
+
+	for _, entry := range config.Resolve {
+		args = append(args, "--resolve", shellQuote(entry))
+	}
+
+	header := func(name, value string) {
+		args = append(args, "-H", shellQuote(name+": "+value))
+	}
+	header("Origin", result.Origin)
+	if config.UserAgent != "" {
+		header("User-Agent", config.UserAgent)
+	}
+	for _, h := range browserHeaders {
+		header(h[0], h[1])
+	}
+	if config.Referer != "" {
+		header("Referer", config.Referer)
+	}
+	if auth := credentials(); auth != "" {
+		header("Authorization", auth)
+	}
+	body := requestBody(methodOf(result))
+	if body != "" && !hasCustomHeader("Content-Type") {
+		header("Content-Type", bodyContentType(body))
+	}
+	for _, h := range customHeaders {
+		header(h[0], h[1])
+	}
+	if methodOf(result) == "OPTIONS" {
+		for _, h := range preflightHeaders() {
+			header(h[0], h[1])
+		}
+	}
+	if t := targetOpts[result.URL]; t != nil {
+		for name, value := range t.Headers {
+			header(name, value)
+		}
+		if t.Token != "" {
+			header("Authorization", authorization(t.Token))
+		}
+	}
+	if cookies := cookiesFor(result.URL); cookies != "" {
+		args = append(args, "-b", shellQuote(cookies))
+	}
+	if body != "" {
+		args = append(args, "--data-raw", shellQuote(body))
+	}
+
+	args = append(args, shellQuote(result.URL))
+	return strings.Join(args, " ")
+}
+
+// cookiesFor returns the --cookies and --cookie-jar values that apply to
+// targetURL, using the same matching as makeRequest.
+func cookiesFor(targetURL string) string {
+	if t := targetOpts[targetURL]; t != nil && t.Cookies != "" {
+		return t.Cookies
+	}
+
+	parsedURL, err := url.Parse(targetURL)
+	if err != nil {
+		return ""
+	}
+
+	var matched []string
+	for _, cookieStr := range config.Cookies {
+		parts := strings.Split(cookieStr, "~~~")
+		if len(parts) == 2 && strings.Contains(parts[0], parsedURL.Host) {
+			matched = append(matched, strings.TrimSpace(parts[1]))
+		}
+	}
+	if cookieJar != nil {
+		for _, c := range cookieJar.Cookies(parsedURL) {
+			matched = append(matched, c.Name+"="+c.Value)
+		}
+	}
+	return strings.Join(matched, "; ")
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dojoFinding is one entry of DefectDojo's "Generic Findings Import" format.
+type dojoFinding struct {
+	Title            string         `json:"title"`
+	Description      string         `json:"description"`
+	Severity         string         `json:"severity"`
+	Date             string         `json:"date"`
+	UniqueIDFromTool string         `json:"unique_id_from_tool"`
+	VulnIDFromTool   string         `json:"vuln_id_from_tool"`
+	Active           bool           `json:"active"`
+	Verified         bool           `json:"verified"`
+	DynamicFinding   bool           `json:"dynamic_finding"`
+	StaticFinding    bool           `json:"static_finding"`
+	Endpoints        []dojoEndpoint `json:"endpoints"`
+}
+
+type dojoEndpoint struct {
+	Protocol string `json:"protocol"`
+	Host     string `json:"host"`
+	Port     int    `json:"port,omitempty"`
+	Path     string `json:"path,omitempty"`
+}
+
+// buildDojoReport converts the results with at least one risk into a
+// Generic Findings Import document, one finding per fingerprint.
+func buildDojoReport() ([]byte, error) {
+	findings := []dojoFinding{}
+	seen := make(map[string]bool)
+	date := time.Now().Format("2006-01-02")
+
+	for _, result := range results {
+		risks := assessRisks(result)
+		if len(risks) == 0 {
+			continue
+		}
+		fp := fingerprint(result)
+		if seen[fp] {
+			continue
+		}
+		seen[fp] = true
+
+		var ids []string
+		for _, risk := range risks {
+			ids = append(ids, risk.ID)
+		}
+
+		finding := dojoFinding{
+			Title:            fmt.Sprintf("CORS misconfiguration (%s test): %s", result.Test, result.URL),
+			Description:      findingEvidence(result),
+			Severity:         maxSeverity(risks).Label(),
+			Date:             date,
+			UniqueIDFromTool: fp,
+			VulnIDFromTool:   strings.Join(ids, ","),
+			Active:           true,
+			DynamicFinding:   true,
+		}
+		if parsedURL, err := url.Parse(result.URL); err == nil {
+			port, _ := strconv.Atoi(parsedURL.Port())
+			finding.Endpoints = []dojoEndpoint{{
+				Protocol: parsedURL.Scheme,
+				Host:     parsedURL.Hostname(),
+				Port:     port,
+				Path:     strings.TrimPrefix(parsedURL.Path, "/"),
+			}}
+		}
+		findings = append(findings, finding)
+	}
+
+	return json.MarshalIndent(map[string]interface{}{"findings": findings}, "", "  ")
+}
+
+func writeDojoExport() {
+	if config.DojoExport == "" {
+		return
+	}
+
+	report, err := buildDojoReport()
+	if err != nil {
+		log.Printf("Error building DefectDojo report: %v", err)
+		emitError(errOutput, config.DojoExport, err)
+		return
+	}
+	if err := os.WriteFile(config.DojoExport, report, 0644); err != nil {
+		log.Printf("Error writing DefectDojo report: %v", err)
+		emitError(errOutput, config.DojoExport, err)
+		return
+	}
+	fmt.Printf("[+] Wrote DefectDojo findings to %s.\n", config.DojoExport)
+	signOutput(config.DojoExport)
+}
+
+// pushDefectDojo reimports the findings into a DefectDojo test. Reimport
+// matches findings on unique_id_from_tool (the finding fingerprint), so
+// repeated scans update the same findings and close ones that disappeared.
+func pushDefectDojo() {
+	if config.DojoURL == "" {
+		return
+	}
+	if config.DojoToken == "" || config.DojoProduct == "" || config.DojoEngagement == "" {
+		fmt.Println("\n[!] DefectDojo push needs --dojo-token, --dojo-product and --dojo-engagement.")
+		emitError(errIntegration, config.DojoURL, fmt.Errorf("missing --dojo-token, --dojo-product or --dojo-engagement"))
+		return
+	}
+
+	report, err := buildDojoReport()
+	if err != nil {
+		fmt.Printf("\n[!] Building DefectDojo report failed: %v\n", err)
+		emitError(errIntegration, config.DojoURL, err)
+		return
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for field, value := range map[string]string{
+		"scan_type":           "Generic Findings Import",
+		"product_name":        config.DojoProduct,
+		"engagement_name":     config.DojoEngagement,
+		"test_title":          config.DojoTest,
+		"auto_create_context": "true",
+		"close_old_findings":  "true",
+		"active":              "true",
+		"verified":            "false",
+	} {
+		writer.WriteField(field, value)
+	}
+	part, err := writer.CreateFormFile("file", "cors-scanner.json")
+	if err != nil {
+		fmt.Printf("\n[!] Building DefectDojo request failed: %v\n", err)
+		emitError(errIntegration, config.DojoURL, err)
+		return
+	}
+	part.Write(report)
+	writer.Close()
+
+	req, err := http.NewRequest("POST", strings.TrimRight(config.DojoURL, "/")+"/api/v2/reimport-scan/", &body)
+	if err != nil {
+		fmt.Printf("\n[!] Building DefectDojo request failed: %v\n", err)
+		emitError(errIntegration, config.DojoURL, err)
+		return
+	}
+	req.Header.Set("Authorization", "Token "+config.DojoToken)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		fmt.Printf("\n[!] DefectDojo push failed: %v\n", err)
+		emitError(errIntegration, config.DojoURL, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		fmt.Printf("\n[!] DefectDojo returned %s: %s\n", resp.Status, strings.TrimSpace(string(msg)))
+		emitError(errIntegration, config.DojoURL, fmt.Errorf("DefectDojo returned %s", resp.Status))
+		return
+	}
+
+	var imported struct {
+		Test int `json:"test"`
+	}
+	json.NewDecoder(resp.Body).Decode(&imported)
+	fmt.Printf("[+] Pushed findings to DefectDojo test %d.\n", imported.Test)
+}
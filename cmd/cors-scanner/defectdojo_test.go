@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// captureErrors sends the --error-log stream to a buffer for one test.
+func captureErrors(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	config.ErrorLog = "-"
+	errorLogOnce.Do(func() {})
+	errorLog = &buf
+	t.Cleanup(func() { config.ErrorLog, errorLog = "", nil })
+	return &buf
+}
+
+func TestPushDefectDojoErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		url     string
+		token   string
+		message string
+	}{
+		{"missing flags", server.URL, "", "missing --dojo-token"},
+		{"rejected", server.URL, "bad", "401 Unauthorized"},
+		{"unreachable", "http://127.0.0.1:1", "bad", "connection refused"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := captureErrors(t)
+			config.DojoURL, config.DojoToken, config.DojoProduct, config.DojoEngagement = tt.url, tt.token, "web", "weekly"
+			defer func() { config.DojoURL, config.DojoToken, config.DojoProduct, config.DojoEngagement = "", "", "", "" }()
+
+			pushDefectDojo()
+
+			var logged scanError
+			if err := json.Unmarshal(errs.Bytes(), &logged); err != nil {
+				t.Fatalf("error log %q: %v", errs.String(), err)
+			}
+			if logged.Code != errIntegration || logged.Target != tt.url || !strings.Contains(logged.Message, tt.message) {
+				t.Errorf("logged %+v, want an %s error for %s mentioning %q", logged, errIntegration, tt.url, tt.message)
+			}
+		})
+	}
+}
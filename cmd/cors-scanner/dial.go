@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// dialContext opens the connection for every outgoing request. With
+// --unix-socket all connections go to the socket and the URL host is only
+// used for the Host header, SNI and origin construction.
+func dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{
+		Timeout:   time.Duration(config.Timeout) * time.Second,
+		KeepAlive: 30 * time.Second,
+		Resolver:  resolver,
+	}
+
+	if config.UnixSocket != "" {
+		return dialer.DialContext(ctx, "unix", config.UnixSocket)
+	}
+	if ip, ok := resolveOverrides[strings.ToLower(addr)]; ok {
+		_, port, _ := net.SplitHostPort(addr)
+		addr = net.JoinHostPort(ip, port)
+	}
+	if len(config.SourceIPs) > 0 {
+		dialer.LocalAddr = &net.TCPAddr{IP: nextSourceIP()}
+	}
+	return dialer.DialContext(ctx, network, addr)
+}
+
+// resolveOverrides maps host:port to the IP --resolve pins it to.
+var resolveOverrides = make(map[string]string)
+
+// lookupHost returns the addresses dialContext would connect to for
+// host and port: the --resolve pin if there is one, or else the answer of
+// --resolver, --doh or the system resolver.
+func lookupHost(ctx context.Context, host, port string) ([]net.IP, error) {
+	if ip, ok := resolveOverrides[net.JoinHostPort(strings.ToLower(host), port)]; ok {
+		return []net.IP{net.ParseIP(ip)}, nil
+	}
+	r := resolver
+	if r == nil {
+		r = net.DefaultResolver
+	}
+	addrs, err := r.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]net.IP, len(addrs))
+	for i, addr := range addrs {
+		ips[i] = addr.IP
+	}
+	return ips, nil
+}
+
+// parseResolve reads --resolve entries in curl's host:port:addr form. Only
+// the connection is redirected; Host, SNI and origins keep the name.
+func parseResolve(entries []string) error {
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" {
+			return fmt.Errorf("invalid --resolve %q, expected host:port:addr", entry)
+		}
+		if _, err := strconv.ParseUint(parts[1], 10, 16); err != nil {
+			return fmt.Errorf("invalid --resolve %q: bad port %q", entry, parts[1])
+		}
+		ip := net.ParseIP(strings.Trim(parts[2], "[]"))
+		if ip == nil {
+			return fmt.Errorf("invalid --resolve %q: %q is not an IP address", entry, parts[2])
+		}
+		resolveOverrides[net.JoinHostPort(strings.ToLower(parts[0]), parts[1])] = ip.String()
+	}
+	return nil
+}
+
+var sourceIPCounter uint64
+
+// nextSourceIP rotates round-robin through the --source-ip addresses, one
+// per connection, to spread a scan across several egress IPs.
+func nextSourceIP() net.IP {
+	n := atomic.AddUint64(&sourceIPCounter, 1) - 1
+	return net.ParseIP(config.SourceIPs[n%uint64(len(config.SourceIPs))])
+}
+
+// validateSourceIP checks that --source-ip is an address this host can
+// send from, so a typo fails fast instead of on every request.
+func validateSourceIP(addr string) error {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return fmt.Errorf("invalid source IP %q", addr)
+	}
+
+	conn, err := net.ListenPacket("udp", net.JoinHostPort(ip.String(), "0"))
+	if err != nil {
+		return fmt.Errorf("cannot send from source IP %s: %v", addr, err)
+	}
+	conn.Close()
+	return nil
+}
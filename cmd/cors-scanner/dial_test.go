@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestParseResolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{name: "IPv4", entry: "api.example.com:443:10.0.0.5", key: "api.example.com:443", want: "10.0.0.5"},
+		{name: "host lower-cased", entry: "API.Example.com:80:10.0.0.6", key: "api.example.com:80", want: "10.0.0.6"},
+		{name: "bracketed IPv6", entry: "api.example.com:443:[2001:db8::1]", key: "api.example.com:443", want: "2001:db8::1"},
+		{name: "bare IPv6", entry: "api.example.com:8443:2001:db8::2", key: "api.example.com:8443", want: "2001:db8::2"},
+		{name: "missing address", entry: "api.example.com:443", wantErr: true},
+		{name: "missing host", entry: ":443:10.0.0.5", wantErr: true},
+		{name: "bad port", entry: "api.example.com:https:10.0.0.5", wantErr: true},
+		{name: "port out of range", entry: "api.example.com:70000:10.0.0.5", wantErr: true},
+		{name: "name instead of address", entry: "api.example.com:443:backend.internal", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolveOverrides = make(map[string]string)
+			defer func() { resolveOverrides = make(map[string]string) }()
+
+			err := parseResolve([]string{tt.entry})
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseResolve(%q) succeeded, want an error", tt.entry)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseResolve(%q): %v", tt.entry, err)
+			}
+			if got := resolveOverrides[tt.key]; got != tt.want {
+				t.Errorf("parseResolve(%q) pinned %s to %q, want %q", tt.entry, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupHostUsesResolve(t *testing.T) {
+	resolveOverrides = make(map[string]string)
+	defer func() { resolveOverrides = make(map[string]string) }()
+	if err := parseResolve([]string{"pinned.invalid:443:192.0.2.10"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ips, err := lookupHost(context.Background(), "Pinned.invalid", "443")
+	if err != nil {
+		t.Fatalf("lookupHost: %v", err)
+	}
+	if len(ips) != 1 || !ips[0].Equal(net.ParseIP("192.0.2.10")) {
+		t.Errorf("lookupHost = %v, want [192.0.2.10]", ips)
+	}
+}
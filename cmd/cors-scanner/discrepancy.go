@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// printMethodDiscrepancies compares the CORS policy a URL returned to each
+// non-preflight request (GET unless --method, --methods or --data say
+// otherwise) with the one it returned to the OPTIONS preflight for the
+// same test, and reports every test where the two disagree. Servers
+// often handle preflights in a separate code path (a gateway, a framework
+// filter) from the one that decorates the real response, and the two drift
+// apart: a preflight that denies an origin the simple response reflects
+// still leaks data to any request that needs no preflight.
+func printMethodDiscrepancies() {
+	preflighted := make(map[string]bool)
+	for _, result := range results {
+		if fuzzedPreflight(result) {
+			continue
+		}
+		if result.Method == "OPTIONS" || containsString(methodsFor(result.URL), "OPTIONS") {
+			preflighted[result.URL] = true
+		}
+	}
+	if len(preflighted) == 0 {
+		return
+	}
+
+	// Both methods use the same origin, so URL, test, origin and vantage
+	// identify the pair; tests can send several origins. A method missing
+	// from a pair received no CORS headers at all.
+	policies := make(map[string]map[string]CORSHeaders)
+	keyURLs := make(map[string]string)
+	var keys []string
+	for _, result := range results {
+		if !preflighted[result.URL] || fuzzedPreflight(result) {
+			continue
+		}
+		key := result.URL + " [" + result.Test + "] " + result.Origin
+		if result.Vantage != "" {
+			key += " via " + result.Vantage
+		}
+		if policies[key] == nil {
+			policies[key] = make(map[string]CORSHeaders)
+			keyURLs[key] = result.URL
+			keys = append(keys, key)
+		}
+		policies[key][methodOf(result)] = result.Headers
+	}
+	sort.Strings(keys)
+
+	fmt.Println("\n" + strings.Repeat("=", 70))
+	fmt.Println("PREFLIGHT / SIMPLE REQUEST COMPARISON")
+	fmt.Println(strings.Repeat("=", 70))
+
+	differing := 0
+	for _, key := range keys {
+		preflight, hasPreflight := policies[key]["OPTIONS"]
+		for _, method := range simpleMethods(keyURLs[key], policies[key]) {
+			simple, hasSimple := policies[key][method]
+			if simple.ACAO == preflight.ACAO && simple.ACAC == preflight.ACAC {
+				continue
+			}
+
+			differing++
+			fmt.Printf("\n%s\n", key)
+			fmt.Printf("    %-10s %s\n", method+":", describePolicy(simple, hasSimple))
+			fmt.Printf("    %-10s %s\n", "OPTIONS:", describePolicy(preflight, hasPreflight))
+			printDiscrepancy(simple, preflight)
+		}
+	}
+
+	if differing == 0 {
+		fmt.Println("\n[*] Preflight and simple responses agreed for every test.")
+	} else {
+		fmt.Printf("\n[!] %d tests got a different policy from the preflight than from the simple request.\n", differing)
+	}
+}
+
+// fuzzedPreflight reports whether a result is one of the preflights
+// --fuzz-methods and --header-reflection add; they have no simple request
+// to compare with.
+func fuzzedPreflight(result ScanResult) bool {
+	return result.Test == testMethodFuzz || result.Test == testHeaderReflection
+}
+
+// simpleMethods returns the non-preflight methods a URL was tested with,
+// including any that only saved results mention.
+func simpleMethods(targetURL string, policies map[string]CORSHeaders) []string {
+	var methods []string
+	for _, method := range methodsFor(targetURL) {
+		if method != "OPTIONS" {
+			methods = append(methods, method)
+		}
+	}
+	for method := range policies {
+		if method != "OPTIONS" && !containsString(methods, method) {
+			methods = append(methods, method)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// printDiscrepancy explains what a disagreement between a simple response
+// and its preflight means in a browser.
+func printDiscrepancy(simple, preflight CORSHeaders) {
+	switch {
+	case simple.ACAO != "" && preflight.ACAO == "":
+		fmt.Println("    [!] The preflight denies an origin the simple response allows; simple requests can still read the response.")
+	case simple.ACAO == "" && preflight.ACAO != "":
+		fmt.Println("    [*] The preflight allows an origin the simple response does not; the actual request will fail in browsers.")
+	case simple.ACAC != preflight.ACAC:
+		fmt.Println("    [!] Credentials are allowed by only one of the two responses.")
+	}
+}
+
+// methodOf returns the request method of a result; results saved before
+// preflight testing existed were all GET requests.
+func methodOf(result ScanResult) string {
+	if result.Method == "" {
+		return "GET"
+	}
+	return result.Method
+}
+
+func describePolicy(headers CORSHeaders, present bool) string {
+	if !present {
+		return "no CORS headers"
+	}
+	return fmt.Sprintf("ACAO=%q ACAC=%q", headers.ACAO, headers.ACAC)
+}
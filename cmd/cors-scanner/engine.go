@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"cors-scanner/pkg/corscan"
+)
+
+// The scan pipeline: the corscan engine set up with the command's request
+// handling, and the results made from its exchanges.
+
+// newEngine configures a corscan.Scanner with the CLI's vantages, methods,
+// origins and request pipeline; its exchanges end up in addResult.
+func newEngine() *corscan.Scanner {
+	var engineVantages []corscan.Vantage
+	for _, v := range vantagePoints() {
+		engineVantages = append(engineVantages, corscan.Vantage{Name: v.Name, Client: buildHTTPClient(v.Proxy)})
+	}
+	preflight := make(http.Header)
+	for _, h := range preflightHeaders() {
+		preflight.Set(h[0], h[1])
+	}
+
+	return corscan.NewScanner(corscan.Config{
+		Tests:           selectedTests,
+		Origins:         originList,
+		Methods:         methodsFor,
+		PreflightHeader: preflight,
+		CaptureHeaders:  config.CaptureHeaders,
+		Vantages:        engineVantages,
+		Send:            sendWithRetry,
+		OnExchange:      handleExchange,
+	})
+}
+
+func testCORSPolicy(ctx context.Context, engine *corscan.Scanner, targetURL string) {
+	if config.PrimeSession {
+		primeSession(ctx, targetURL)
+	}
+
+	// Failed requests are reported by handleExchange
+	engine.ScanURL(ctx, targetURL)
+
+	if config.FuzzMethods && ctx.Err() == nil {
+		fuzzPreflightMethods(ctx, engine, targetURL)
+	}
+	if config.HeaderReflection && ctx.Err() == nil {
+		probeHeaderReflection(ctx, engine, targetURL)
+	}
+}
+
+// handleExchange turns a test exchange of the engine into a result, with
+// everything the CLI records beyond the CORS headers.
+func handleExchange(ctx context.Context, x *corscan.Exchange) {
+	test := x.Probe.Test
+	metrics.Incr("requests", "test:"+test)
+	metrics.Timing("request.duration", x.Duration, "test:"+test)
+	// Requests cut off by Ctrl-C or --max-scan-time did not fail; their
+	// URLs are scanned again on resume anyway
+	if x.Err != nil && (errors.Is(x.Err, context.Canceled) || ctx.Err() != nil) {
+		return
+	}
+	if x.Err != nil {
+		metrics.Incr("request.errors", "test:"+test)
+		emitError(requestErrorCode(x.Err), x.Request.URL, x.Err)
+		recordFailure(x.Request.URL, test, x.Err)
+		if config.Verbose {
+			fmt.Printf("Error making request: %v\n", x.Err)
+		}
+		return
+	}
+	resp := x.Response
+	if !statusWanted(resp.StatusCode) {
+		return
+	}
+
+	result := ScanResult{
+		Result:      x.Result,
+		Certificate: certInfo(resp),
+		Tech:        techInfo(resp),
+		Cache:       cacheInfo(resp),
+		Redirects:   redirectChain(resp),
+	}
+	switch test {
+	case testMethodFuzz:
+		result.FuzzMethod = x.Request.Header.Get("Access-Control-Request-Method")
+	case testHeaderReflection:
+		result.FuzzHeader = x.Request.Header.Get("Access-Control-Request-Headers")
+	}
+	var err error
+	if config.PostHook != "" {
+		if result.Hook, err = runPostHook(resp, test, x.Vantage.Name); err != nil {
+			log.Printf("Error running post-hook for %s: %v", result.URL, err)
+			emitError(errHook, result.URL, err)
+		}
+	}
+	if config.Cluster {
+		result.Signature = responseSignature(resp, result.URL)
+	}
+	resp.Body.Close()
+	if test == "reflected" && result.Headers.ACAO == result.Origin {
+		result.CharProbe = probeReflectionChars(ctx, x.Vantage.Client, result.Method, result.URL, result.Origin)
+	}
+	addResult(ctx, result)
+}
+
+func addResult(ctx context.Context, result ScanResult) {
+	var err error
+	if result.CustomRisks, err = evaluateRules(result); err != nil {
+		log.Printf("Error evaluating rules for %s: %v", result.URL, err)
+		emitError(errRule, result.URL, err)
+	}
+
+	// Custom rules may flag responses that carry no CORS headers at all.
+	if !result.Headers.Empty() || len(result.CustomRisks) > 0 {
+		result.ScannedAt = time.Now().UTC()
+		assess(&result)
+		result.Remediation = remediationFor(result.Risks, result.Tech)
+		if config.Verify && containsString(confirmableClasses, result.Class) {
+			result.Verification = confirmFinding(ctx, result)
+		}
+		if config.VerifyHeadless && exploitable(result) {
+			result.Headless = verifyHeadless(ctx, result)
+		}
+		appendResults(result)
+		streamResult(result)
+
+		metrics.Incr("findings", "test:"+result.Test, "severity:"+result.Severity.String())
+
+		if config.Verbose {
+			headers := result.Headers
+			if result.Vantage != "" {
+				fmt.Printf("Vantage: %s\n", result.Vantage)
+			}
+			fmt.Printf("Origin: %s\n", result.Origin)
+			fmt.Printf("Protocol: %s\n", result.Protocol)
+			fmt.Printf("Status: %d\n", result.Status)
+			if headers.ACAO != "" {
+				fmt.Printf("ACAO: %s\n", headers.ACAO)
+			}
+			if headers.ACAC != "" {
+				fmt.Printf("ACAC: %s\n", headers.ACAC)
+			}
+			if headers.ACAM != "" {
+				fmt.Printf("ACAM: %s\n", headers.ACAM)
+			}
+			if headers.ACAH != "" {
+				fmt.Printf("ACAH: %s\n", headers.ACAH)
+			}
+			if headers.ACMA != "" {
+				fmt.Printf("ACMA: %s\n", headers.ACMA)
+			}
+			if headers.ACEH != "" {
+				fmt.Printf("ACEH: %s\n", headers.ACEH)
+			}
+			if headers.ACAPN != "" {
+				fmt.Printf("ACAPN: %s\n", headers.ACAPN)
+			}
+			if result.Encoding != "" {
+				fmt.Printf("Content-Encoding: %s\n", result.Encoding)
+			}
+			if config.Curl {
+				fmt.Printf("Reproduce: %s\n", curlCommand(result))
+			}
+			fmt.Println()
+		}
+	}
+}
+
+// appendResults adds results to the scan's results and their index.
+func appendResults(found ...ScanResult) {
+	resultsMux.Lock()
+	defer resultsMux.Unlock()
+	for _, result := range found {
+		resultIndex[result.URL] = append(resultIndex[result.URL], len(results))
+		results = append(results, result)
+	}
+}
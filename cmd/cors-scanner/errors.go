@@ -0,0 +1,110 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Error codes written to the --error-log stream. target.* codes mean the
+// target failed or misbehaved; the others point at the scanner's own
+// configuration, input or outputs.
+const (
+	errTargetDNS     = "target.dns"
+	errTargetRefused = "target.refused"
+	errTargetTimeout = "target.timeout"
+	errTargetTLS     = "target.tls"
+	errTargetRequest = "target.request"
+	errOutOfScope    = "input.out_of_scope"
+	errHook          = "config.hook"
+	errRule          = "config.rule"
+	errQueue         = "queue"
+	errOutput        = "output.write"
+	errIntegration   = "integration"
+)
+
+var (
+	// errScopeRefused and errHookFailed mark request errors the scanner
+	// raised itself rather than the target.
+	errScopeRefused = errors.New("out of scope")
+	errHookFailed   = errors.New("hook failed")
+)
+
+// scanError is one line of the --error-log stream.
+type scanError struct {
+	Time    time.Time `json:"time"`
+	Code    string    `json:"code"`
+	Target  string    `json:"target,omitempty"`
+	Message string    `json:"message"`
+}
+
+var (
+	errorLog     io.Writer
+	errorLogOnce sync.Once
+	errorLogMux  sync.Mutex
+)
+
+// emitError writes a non-fatal error as a JSON line to --error-log ("-"
+// for stderr). The human-readable messages are printed as before.
+func emitError(code, target string, err error) {
+	if config.ErrorLog == "" {
+		return
+	}
+	errorLogOnce.Do(func() {
+		if config.ErrorLog == "-" {
+			errorLog = os.Stderr
+			return
+		}
+		file, err := os.OpenFile(config.ErrorLog, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			log.Printf("Error opening error log: %v", err)
+			return
+		}
+		errorLog = file
+	})
+	if errorLog == nil {
+		return
+	}
+
+	line, marshalErr := json.Marshal(scanError{Time: time.Now().UTC(), Code: code, Target: target, Message: err.Error()})
+	if marshalErr != nil {
+		return
+	}
+	errorLogMux.Lock()
+	defer errorLogMux.Unlock()
+	errorLog.Write(append(line, '\n'))
+}
+
+// requestErrorCode classifies an error returned for a request.
+func requestErrorCode(err error) string {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var recordErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+
+	switch {
+	case errors.Is(err, errScopeRefused):
+		return errOutOfScope
+	case errors.Is(err, errHookFailed):
+		return errHook
+	case errors.As(err, &dnsErr):
+		return errTargetDNS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return errTargetRefused
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return errTargetTimeout
+	case errors.As(err, &recordErr), errors.As(err, &alertErr), errors.As(err, &certErr), errors.As(err, &unknownAuthority):
+		return errTargetTLS
+	}
+	return errTargetRequest
+}
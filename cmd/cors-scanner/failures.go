@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// failedTarget is a URL with tests whose requests failed after every
+// retry, so they never tested anything.
+type failedTarget struct {
+	URL   string
+	Tests []string
+	Err   string // the last error
+}
+
+var (
+	failures    = make(map[string]*failedTarget)
+	failedURLs  []string
+	failuresMux sync.Mutex
+	// failedTLS is set once a request failed certificate verification.
+	failedTLS bool
+)
+
+// recordFailure notes a test whose request failed permanently.
+func recordFailure(targetURL, test string, err error) {
+	failuresMux.Lock()
+	defer failuresMux.Unlock()
+
+	failed := failures[targetURL]
+	if failed == nil {
+		failed = &failedTarget{URL: targetURL}
+		failures[targetURL] = failed
+		failedURLs = append(failedURLs, targetURL)
+	}
+	if !containsString(failed.Tests, test) {
+		failed.Tests = append(failed.Tests, test)
+	}
+	failed.Err = err.Error()
+	if requestErrorCode(err) == errTargetTLS {
+		failedTLS = true
+	}
+}
+
+// hasFailures reports whether any request to targetURL failed for good.
+func hasFailures(targetURL string) bool {
+	failuresMux.Lock()
+	defer failuresMux.Unlock()
+	return failures[targetURL] != nil
+}
+
+// printFailures lists the URLs with untested tests, which would otherwise
+// look the same as URLs without CORS headers.
+func printFailures() {
+	if len(failedURLs) == 0 {
+		return
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 70))
+	fmt.Printf("FAILED REQUESTS - %d URLs were not fully tested\n", len(failedURLs))
+	fmt.Println(strings.Repeat("=", 70))
+	for i, u := range failedURLs {
+		if i == 10 && len(failedURLs) > 11 {
+			fmt.Printf("\n... and %d more", len(failedURLs)-10)
+			if config.FailedFile == "" {
+				fmt.Print(" (list them all with --failed-urls)")
+			}
+			fmt.Println()
+			break
+		}
+		failed := failures[u]
+		fmt.Printf("\n%s\n    Tests: %s\n    Error: %s\n", u, strings.Join(failed.Tests, ", "), failed.Err)
+	}
+	if failedTLS && !config.Insecure {
+		fmt.Println("\n[!] TLS errors: trust a private CA with --ca-cert, or skip verification with --insecure.")
+	}
+}
+
+// writeFailedURLs writes the URLs of printFailures one per line, ready to
+// be scanned again with --url-file.
+func writeFailedURLs() {
+	if config.FailedFile == "" || len(failedURLs) == 0 {
+		return
+	}
+	content := strings.Join(failedURLs, "\n") + "\n"
+	if err := os.WriteFile(config.FailedFile, []byte(content), 0644); err != nil {
+		log.Printf("Error writing failed URLs: %v", err)
+		emitError(errOutput, config.FailedFile, err)
+		return
+	}
+	fmt.Printf("[+] Wrote %d URLs with failed requests to %s.\n", len(failedURLs), config.FailedFile)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"cors-scanner/pkg/corscan"
+)
+
+func TestHandleExchangeFailures(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		err    error
+		failed bool
+	}{
+		{"connection refused", context.Background(), errors.New("dial tcp: connection refused"), true},
+		{"scan cancelled", cancelled, context.Canceled, false},
+		{"cancelled request wrapped", context.Background(), fmt.Errorf("Get: %w", context.Canceled), false},
+		{"error after the deadline", cancelled, errors.New("read: connection reset by peer"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() { failures, failedURLs = make(map[string]*failedTarget), nil }()
+
+			x := &corscan.Exchange{
+				Probe:   corscan.Probe{Test: "reflected"},
+				Request: corscan.Request{URL: "https://api.example.com/"},
+				Err:     tt.err,
+			}
+			handleExchange(tt.ctx, x)
+			if got := hasFailures(x.Request.URL); got != tt.failed {
+				t.Errorf("hasFailures = %v, want %v", got, tt.failed)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const githubIssueLabel = "cors-scanner"
+
+type githubClient struct {
+	apiURL string
+	repo   string
+	token  string
+	client *http.Client
+}
+
+func newGitHubClient(repo, token string) *githubClient {
+	apiURL := os.Getenv("GITHUB_API_URL")
+	if apiURL == "" {
+		apiURL = "https://api.github.com"
+	}
+	return &githubClient{
+		apiURL: strings.TrimRight(apiURL, "/"),
+		repo:   repo,
+		token:  token,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (g *githubClient) do(method, path string, payload interface{}) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, g.apiURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+g.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("github returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+// knownFingerprints collects the fingerprints of every issue (open or
+// closed) this tool has filed before, so closed findings are not reopened.
+func (g *githubClient) knownFingerprints() (map[string]bool, error) {
+	known := make(map[string]bool)
+
+	for page := 1; ; page++ {
+		resp, err := g.do("GET", fmt.Sprintf("/repos/%s/issues?state=all&labels=%s&per_page=100&page=%d", g.repo, githubIssueLabel, page), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var issues []struct {
+			Body string `json:"body"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&issues)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, issue := range issues {
+			for _, line := range strings.Split(issue.Body, "\n") {
+				if fp, ok := strings.CutPrefix(strings.TrimSpace(line), "Fingerprint: "); ok {
+					known[fp] = true
+				}
+			}
+		}
+		if len(issues) < 100 {
+			return known, nil
+		}
+	}
+}
+
+func (g *githubClient) createIssue(title, body string, labels []string) (int, error) {
+	resp, err := g.do("POST", "/repos/"+g.repo+"/issues", map[string]interface{}{
+		"title":  title,
+		"body":   body,
+		"labels": labels,
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var created struct {
+		Number int `json:"number"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return 0, err
+	}
+	return created.Number, nil
+}
+
+// pushGitHubIssues files an issue for every finding with at least one risk
+// that has not been filed before.
+func pushGitHubIssues() {
+	if config.GitHubRepo == "" {
+		return
+	}
+	token := config.GitHubToken
+	if token == "" {
+		token = os.Getenv("GITHUB_TOKEN")
+	}
+	if token == "" {
+		fmt.Println("\n[!] GitHub integration needs --github-token or GITHUB_TOKEN.")
+		return
+	}
+
+	github := newGitHubClient(config.GitHubRepo, token)
+	known, err := github.knownFingerprints()
+	if err != nil {
+		fmt.Printf("\n[!] Listing GitHub issues failed: %v\n", err)
+		emitError(errIntegration, config.GitHubRepo, err)
+		return
+	}
+
+	created := 0
+	for _, result := range results {
+		risks := assessRisks(result)
+		if len(risks) == 0 {
+			continue
+		}
+
+		fp := fingerprint(result)
+		if known[fp] {
+			continue
+		}
+		known[fp] = true
+
+		severity := maxSeverity(risks)
+		title := fmt.Sprintf("[%s] CORS misconfiguration: %s", strings.ToUpper(severity.String()), result.URL)
+		body := "```\n" + findingEvidence(result) + "```\n\n" +
+			"Reproduce with:\n\n```sh\n" + curlCommand(result) + "\n```\n"
+
+		number, err := github.createIssue(title, body, []string{githubIssueLabel, "severity: " + severity.String()})
+		if err != nil {
+			fmt.Printf("[!] GitHub issue creation failed for %s: %v\n", result.URL, err)
+			emitError(errIntegration, result.URL, err)
+			continue
+		}
+
+		created++
+		fmt.Printf("[+] Opened GitHub issue #%d for %s\n", number, result.URL)
+	}
+
+	fmt.Printf("[*] GitHub: %d new issues opened.\n", created)
+}
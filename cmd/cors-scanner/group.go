@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// findingGroup collapses the results of all tests against one URL into a
+// single finding for --group.
+type findingGroup struct {
+	URL       string
+	Results   []ScanResult
+	Reflected []string // origins the URL echoed back in Access-Control-Allow-Origin
+	Triggered []string // tests whose result carries a risk
+	Risks     []Risk   // distinct risks, most severe occurrence of each
+}
+
+// groupByURL groups results by URL in the order the URLs first appear.
+func groupByURL(list []ScanResult) []*findingGroup {
+	var groups []*findingGroup
+	byURL := make(map[string]*findingGroup)
+
+	for _, result := range list {
+		group := byURL[result.URL]
+		if group == nil {
+			group = &findingGroup{URL: result.URL}
+			byURL[result.URL] = group
+			groups = append(groups, group)
+		}
+		group.Results = append(group.Results, result)
+
+		if result.Origin != "" && result.Headers.ACAO == result.Origin && !containsString(group.Reflected, result.Origin) {
+			group.Reflected = append(group.Reflected, result.Origin)
+		}
+		risks := assessRisks(result)
+		if test := testName(result); len(risks) > 0 && !containsString(group.Triggered, test) {
+			group.Triggered = append(group.Triggered, test)
+		}
+		for _, risk := range risks {
+			group.addRisk(risk)
+		}
+	}
+	return groups
+}
+
+func (g *findingGroup) addRisk(risk Risk) {
+	for i, existing := range g.Risks {
+		if existing.ID == risk.ID {
+			if risk.Severity > existing.Severity {
+				g.Risks[i] = risk
+			}
+			return
+		}
+	}
+	g.Risks = append(g.Risks, risk)
+}
+
+// testName names the test behind a result; results saved before tests
+// were recorded fall back to their origin.
+func testName(result ScanResult) string {
+	if result.Test != "" {
+		return result.Test
+	}
+	return result.Origin
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func listOrNone(list []string) string {
+	if len(list) == 0 {
+		return "none"
+	}
+	return strings.Join(list, ", ")
+}
+
+func printGroupedResults(groups []*findingGroup, sizes map[string]int) {
+	for i, group := range groups {
+		fmt.Printf("\n[%d] URL: %s\n", i+1, group.URL)
+		fmt.Printf("    Severity: %s\n", maxSeverity(group.Risks).Label())
+		fmt.Printf("    Reflected origins: %s\n", listOrNone(group.Reflected))
+		fmt.Printf("    Triggered tests: %s\n", listOrNone(group.Triggered))
+		if first := group.Results[0]; first.Cluster != "" {
+			fmt.Printf("    Cluster: %s\n", clusterLabel(first, sizes))
+		}
+		for _, result := range group.Results {
+			fmt.Printf("    - %-24s Origin: %s  ACAO: %s  ACAC: %s  Finding: %s\n", testName(result), result.Origin,
+				result.Headers.ACAO, result.Headers.ACAC, fingerprint(result))
+			if config.Curl {
+				fmt.Printf("      %s\n", curlCommand(result))
+			}
+		}
+		for _, risk := range group.Risks {
+			fmt.Printf("    %s: %s\n", strings.ToUpper(risk.Severity.String()), risk.Message)
+		}
+	}
+}
+
+func writeHTMLGroups(b *strings.Builder, groups []*findingGroup, sizes map[string]int) {
+	for i, group := range groups {
+		severity := maxSeverity(group.Risks)
+		fmt.Fprintf(b, "<h2>[%d] %s</h2>\n<table>\n", i+1, html.EscapeString(group.URL))
+		fmt.Fprintf(b, "<tr><th>Severity</th><td class=\"sev %s\">%s</td></tr>\n", severity, severity)
+		fmt.Fprintf(b, "<tr><th>Reflected origins</th><td><code>%s</code></td></tr>\n", html.EscapeString(listOrNone(group.Reflected)))
+		fmt.Fprintf(b, "<tr><th>Triggered tests</th><td>%s</td></tr>\n", html.EscapeString(listOrNone(group.Triggered)))
+		if first := group.Results[0]; first.Tech != nil {
+			fmt.Fprintf(b, "<tr><th>Stack</th><td>%s</td></tr>\n", html.EscapeString(first.Tech.Summary()))
+		}
+		if first := group.Results[0]; first.Cluster != "" {
+			fmt.Fprintf(b, "<tr><th>Cluster</th><td>%s</td></tr>\n", html.EscapeString(clusterLabel(first, sizes)))
+		}
+		b.WriteString("</table>\n")
+
+		b.WriteString("<table>\n<tr><th>Test</th><th>Origin</th><th>ACAO</th><th>ACAC</th><th>Finding</th></tr>\n")
+		for _, result := range group.Results {
+			fmt.Fprintf(b, "<tr><td>%s</td><td><code>%s</code></td><td><code>%s</code></td><td>%s</td><td><code>%s</code></td></tr>\n",
+				html.EscapeString(testName(result)), html.EscapeString(result.Origin), html.EscapeString(result.Headers.ACAO),
+				html.EscapeString(result.Headers.ACAC), fingerprint(result))
+		}
+		b.WriteString("</table>\n")
+		if config.Curl {
+			b.WriteString("<pre>")
+			for _, result := range group.Results {
+				b.WriteString(html.EscapeString(curlCommand(result)) + "\n")
+			}
+			b.WriteString("</pre>\n")
+		}
+
+		if len(group.Risks) > 0 {
+			b.WriteString("<ul>\n")
+			for _, risk := range group.Risks {
+				fmt.Fprintf(b, "<li><span class=\"sev %s\">%s</span> %s</li>\n", risk.Severity, risk.Severity, html.EscapeString(risk.Message))
+			}
+			b.WriteString("</ul>\n")
+		}
+		writeHTMLRemediation(b, remediationFor(group.Risks, group.Results[0].Tech))
+	}
+}
+
+func writeMarkdownGroups(b *strings.Builder, groups []*findingGroup, sizes map[string]int) {
+	for i, group := range groups {
+		fmt.Fprintf(b, "\n## [%d] %s\n\n", i+1, group.URL)
+		fmt.Fprintf(b, "- **Severity:** %s\n", maxSeverity(group.Risks).Label())
+		fmt.Fprintf(b, "- **Reflected origins:** %s\n", listOrNone(group.Reflected))
+		fmt.Fprintf(b, "- **Triggered tests:** %s\n", listOrNone(group.Triggered))
+		if first := group.Results[0]; first.Tech != nil {
+			fmt.Fprintf(b, "- **Stack:** %s\n", first.Tech.Summary())
+		}
+		if first := group.Results[0]; first.Cluster != "" {
+			fmt.Fprintf(b, "- **Cluster:** %s\n", clusterLabel(first, sizes))
+		}
+
+		b.WriteString("\n| Test | Origin | ACAO | ACAC | Finding |\n|------|--------|------|------|---------|\n")
+		for _, result := range group.Results {
+			fmt.Fprintf(b, "| %s | `%s` | `%s` | %s | `%s` |\n", testName(result), result.Origin, result.Headers.ACAO,
+				result.Headers.ACAC, fingerprint(result))
+		}
+		if config.Curl {
+			b.WriteString("\n```sh\n")
+			for _, result := range group.Results {
+				b.WriteString(curlCommand(result) + "\n")
+			}
+			b.WriteString("```\n")
+		}
+		for _, risk := range group.Risks {
+			fmt.Fprintf(b, "\n> **%s:** %s\n", strings.ToUpper(risk.Severity.String()), risk.Message)
+		}
+		writeMarkdownRemediation(b, remediationFor(group.Risks, group.Results[0].Tech))
+	}
+}
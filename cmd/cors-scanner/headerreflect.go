@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+
+	"cors-scanner/pkg/corscan"
+)
+
+// testHeaderReflection is the test name of --header-reflection results.
+const testHeaderReflection = "header-reflection"
+
+// HeaderReflection is a URL whose preflight echoed an unknown request
+// header back in Access-Control-Allow-Headers.
+type HeaderReflection struct {
+	Origin      string
+	Header      string
+	Credentials bool
+}
+
+// probeHeaderReflection sends a preflight from a random foreign origin
+// asking for a random custom header, from every vantage point. A server
+// that lists it in Access-Control-Allow-Headers copies the request instead
+// of checking an allowlist, and will allow any header an attacker's
+// request needs. The responses become results of the header-reflection
+// test, with the header asked about as FuzzHeader.
+func probeHeaderReflection(ctx context.Context, engine *corscan.Scanner, targetURL string) {
+	probes, err := corscan.Probes(targetURL, "reflected")
+	if err != nil {
+		return
+	}
+	origin := probes[0].Origin
+	header := randomHeaderName()
+
+	for _, v := range engine.Vantages() {
+		if ctx.Err() != nil {
+			return
+		}
+		engine.Do(ctx, v, testHeaderReflection, corscan.Request{
+			Method: http.MethodOptions,
+			URL:    targetURL,
+			Origin: origin,
+			Header: http.Header{
+				"Access-Control-Request-Method":  {http.MethodGet},
+				"Access-Control-Request-Headers": {header},
+			},
+		})
+	}
+}
+
+// headerEchoed reports whether a header-reflection result listed the
+// header its preflight asked for.
+func headerEchoed(result ScanResult) bool {
+	return result.FuzzHeader != "" && corscan.ContainsToken(result.Headers.ACAH, result.FuzzHeader)
+}
+
+// headerReflectionRisks reports a --header-reflection preflight whose
+// response echoed the random header.
+func headerReflectionRisks(result ScanResult) []Risk {
+	if result.Test != testHeaderReflection || !headerEchoed(result) {
+		return nil
+	}
+	if result.Headers.ACAC == "true" {
+		return []Risk{{ID: "header-reflection-credentials", Severity: SeverityMedium, Message: "Access-Control-Request-Headers echoed with credentials - any header a foreign page sends is allowed on authenticated requests"}}
+	}
+	return []Risk{{ID: "header-reflection", Severity: SeverityLow, Message: "Access-Control-Request-Headers echoed instead of checked against an allowlist"}}
+}
+
+func randomHeaderName() string {
+	const charset = "abcdefghijklmnopqrstuvwxyz"
+	name := make([]byte, 10)
+	for i := range name {
+		name[i] = charset[rand.Intn(len(charset))]
+	}
+	return "x-" + string(name)
+}
+
+// printHeaderReflections lists the URLs that echoed the probe header.
+func printHeaderReflections() {
+	if !config.HeaderReflection {
+		return
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 70))
+	fmt.Println("ACCESS-CONTROL-ALLOW-HEADERS REFLECTION")
+	fmt.Println(strings.Repeat("=", 70))
+	headerReflections := make(map[string]*HeaderReflection)
+	for _, result := range results {
+		if result.Test != testHeaderReflection || !headerEchoed(result) {
+			continue
+		}
+		key := result.URL
+		if result.Vantage != "" {
+			key += " via " + result.Vantage
+		}
+		headerReflections[key] = &HeaderReflection{
+			Origin:      result.Origin,
+			Header:      result.FuzzHeader,
+			Credentials: result.Headers.ACAC == "true",
+		}
+	}
+	if len(headerReflections) == 0 {
+		fmt.Println("\n[*] No URL echoed the probe header in Access-Control-Allow-Headers.")
+		return
+	}
+
+	keys := make([]string, 0, len(headerReflections))
+	for key := range headerReflections {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		r := headerReflections[key]
+		fmt.Printf("\n%s\n    Origin: %s\n    Echoed: %s\n", key, r.Origin, r.Header)
+		if r.Credentials {
+			fmt.Println("    [!] With credentials: any header a foreign page sends is allowed on authenticated requests.")
+		}
+	}
+	fmt.Printf("\n[!] %d URLs reflect Access-Control-Request-Headers instead of checking an allowlist.\n", len(keys))
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// customHeaders are the --custom-header name/value pairs, sent with every
+// test request in the order given.
+var customHeaders [][2]string
+
+// parseHeader reads a header given as "Name: value" or "Name~~~value".
+func parseHeader(value string) ([2]string, error) {
+	name, val, ok := strings.Cut(value, "~~~")
+	if !ok {
+		name, val, ok = strings.Cut(value, ":")
+	}
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || strings.ContainsAny(name, " \t") {
+		return [2]string{}, fmt.Errorf("invalid header %q, expected \"Name: value\" or Name~~~value", value)
+	}
+	return [2]string{name, strings.TrimSpace(val)}, nil
+}
+
+// skippedFileHeaders are left out of a --headers-file: the scanner sets
+// them per request, and Origin is what every test varies.
+var skippedFileHeaders = []string{"Origin", "Host", "Content-Length", "Connection"}
+
+// loadHeadersFile reads "Name: value" lines, such as a request copied from
+// Burp. A leading request line, blank-line-separated body and # comments
+// are ignored.
+func loadHeadersFile(path string) ([][2]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open headers file: %v", err)
+	}
+	defer file.Close()
+
+	var headers [][2]string
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			if len(headers) > 0 {
+				break // the request body follows
+			}
+			continue
+		}
+		if strings.HasPrefix(text, "#") || (line == 1 && strings.Contains(text, " HTTP/")) {
+			continue
+		}
+		header, err := parseHeader(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		if containsString(skippedFileHeaders, http.CanonicalHeaderKey(header[0])) {
+			continue
+		}
+		headers = append(headers, header)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	return headers, nil
+}
+
+// hasCustomHeader reports whether a custom header of the given name is set.
+func hasCustomHeader(name string) bool {
+	for _, h := range customHeaders {
+		if http.CanonicalHeaderKey(h[0]) == http.CanonicalHeaderKey(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCustomHeaders collects the --headers-file and --custom-header
+// headers. A later header replaces an earlier one of the same name, so
+// the command line wins over the file.
+func parseCustomHeaders(values []string) error {
+	var headers [][2]string
+	if config.HeadersFile != "" {
+		var err error
+		if headers, err = loadHeadersFile(config.HeadersFile); err != nil {
+			return err
+		}
+	}
+	for _, value := range values {
+		header, err := parseHeader(value)
+		if err != nil {
+			return err
+		}
+		headers = append(headers, header)
+	}
+
+	customHeaders = nil
+	index := make(map[string]int)
+	for _, header := range headers {
+		name := http.CanonicalHeaderKey(header[0])
+		if i, ok := index[name]; ok {
+			customHeaders[i] = header
+			continue
+		}
+		index[name] = len(customHeaders)
+		customHeaders = append(customHeaders, header)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoadHeadersFile(t *testing.T) {
+	request := "GET /api/me HTTP/1.1\n" +
+		"Host: api.example.com\n" +
+		"Origin: https://app.example.com\n" +
+		"# session of the test account\n" +
+		"Authorization: Bearer abc123\n" +
+		"X-Api-Key~~~k:1\n" +
+		"content-length: 17\n" +
+		"\n" +
+		"Not-A-Header: body\n"
+
+	got, err := loadHeadersFile(writeTemp(t, "request.txt", request))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][2]string{{"Authorization", "Bearer abc123"}, {"X-Api-Key", "k:1"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loadHeadersFile = %v, want %v", got, want)
+	}
+
+	if _, err := loadHeadersFile(writeTemp(t, "bad.txt", "Authorization: Bearer abc123\nnot a header\n")); err == nil {
+		t.Error("loadHeadersFile accepted a line without a header")
+	}
+}
+
+func TestParseCustomHeaders(t *testing.T) {
+	defer func(file string) {
+		config.HeadersFile = file
+		customHeaders = nil
+	}(config.HeadersFile)
+
+	config.HeadersFile = writeTemp(t, "headers.txt", "Authorization: Bearer from-file\nX-Tenant: acme\n")
+	if err := parseCustomHeaders([]string{"authorization: Bearer from-flag", "X-Debug~~~1"}); err != nil {
+		t.Fatal(err)
+	}
+	want := [][2]string{{"authorization", "Bearer from-flag"}, {"X-Tenant", "acme"}, {"X-Debug", "1"}}
+	if !reflect.DeepEqual(customHeaders, want) {
+		t.Errorf("customHeaders = %v, want %v", customHeaders, want)
+	}
+	if !hasCustomHeader("x-tenant") || hasCustomHeader("Cookie") {
+		t.Errorf("hasCustomHeader does not match %v", customHeaders)
+	}
+}
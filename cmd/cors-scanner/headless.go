@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// headlessPoCOrigin hosts the PoC of null-origin findings, whose page can
+// live anywhere since it runs in a sandboxed iframe. The name never
+// resolves; requests to it are answered from inside the browser.
+const headlessPoCOrigin = "https://cors-poc.invalid"
+
+// Outcomes of --verify-headless.
+const (
+	headlessExploited = "exploited"
+	headlessBlocked   = "blocked"
+	headlessNoReport  = "no-report"
+)
+
+// verifyHeadless loads the PoC of a finding in headless Chrome during the
+// scan and returns whether it read the target, or "" when the browser
+// could not run it.
+func verifyHeadless(ctx context.Context, result ScanResult) string {
+	if err := waitToSend(ctx, result.URL); err != nil {
+		return ""
+	}
+	event, ok, err := runHeadlessPoC(ctx, result, config.ChromePath, vantageProxy(result.Vantage), config.HeadlessWait)
+	switch {
+	case err != nil:
+		if ctx.Err() == nil {
+			log.Printf("Error running headless Chrome for %s: %v", result.URL, err)
+		}
+		return ""
+	case !ok:
+		return headlessNoReport
+	case event.Blocked:
+		return headlessBlocked
+	}
+	return headlessExploited
+}
+
+// headlessOrigin returns the origin a finding's PoC page is served from:
+// the origin the finding was found with, as a browser sends it. Tests
+// send some origins as a bare host, which a browser page on that host
+// sends with the scheme of the target.
+func headlessOrigin(result ScanResult) (string, error) {
+	if result.Origin == "null" {
+		return headlessPoCOrigin, nil
+	}
+	origin := result.Origin
+	if !strings.Contains(origin, "://") {
+		if target, err := url.Parse(result.URL); err == nil && target.Scheme != "" {
+			origin = target.Scheme + "://" + origin
+		}
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("origin %q cannot be loaded in a browser", result.Origin)
+	}
+	return parsed.Scheme + "://" + parsed.Host, nil
+}
+
+// chromeProxy turns a --proxy or --vantage proxy into a Chrome
+// --proxy-server value and the credentials to answer its challenges with,
+// since Chrome takes none in the proxy URL. Chrome's socks5 already
+// resolves names at the proxy; it cannot authenticate to SOCKS proxies.
+func chromeProxy(proxy string) (string, *url.Userinfo, error) {
+	proxyURL, err := parseProxy(proxy)
+	if err != nil {
+		return "", nil, err
+	}
+	scheme := proxyURL.Scheme
+	if scheme == "socks5h" {
+		scheme = "socks5"
+	}
+	if scheme == "socks5" && proxyURL.User != nil {
+		return "", nil, fmt.Errorf("headless Chrome cannot authenticate to SOCKS proxy %s", proxyURL.Redacted())
+	}
+	return scheme + "://" + proxyURL.Host, proxyURL.User, nil
+}
+
+// runHeadlessPoC loads the PoC for a finding in headless Chrome and waits
+// for its outcome. The browser navigates to the finding's origin and the
+// page is answered from inside the browser, so the target sees the real
+// Origin header and is requested live, with the scanner's cookies for it.
+// Certificates are checked like the scanner's own requests: --insecure
+// skips the check and --ca-cert keys are trusted.
+func runHeadlessPoC(ctx context.Context, result ScanResult, chromePath, proxy string, timeout time.Duration) (captureEvent, bool, error) {
+	origin, err := headlessOrigin(result)
+	if err != nil {
+		return captureEvent{}, false, err
+	}
+	pageURL := origin + "/"
+	captureURL := origin + "/__cors_scanner_capture"
+	page := buildPoC(result, captureURL)
+
+	opts := chromedp.DefaultExecAllocatorOptions[:]
+	if config.Insecure {
+		opts = append(opts, chromedp.Flag("ignore-certificate-errors", true))
+	} else if len(caSPKIs) > 0 {
+		opts = append(opts, chromedp.Flag("ignore-certificate-errors-spki-list", strings.Join(caSPKIs, ",")))
+	}
+	if chromePath != "" {
+		opts = append(opts, chromedp.ExecPath(chromePath))
+	}
+	var proxyUser *url.Userinfo
+	if proxy != "" {
+		var server string
+		if server, proxyUser, err = chromeProxy(proxy); err != nil {
+			return captureEvent{}, false, err
+		}
+		opts = append(opts, chromedp.ProxyServer(server))
+	}
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(ctx, opts...)
+	defer cancelAlloc()
+	ctx, cancel := chromedp.NewContext(allocCtx)
+	defer cancel()
+
+	captures := make(chan captureEvent, 1)
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		if auth, ok := ev.(*fetch.EventAuthRequired); ok {
+			response := &fetch.AuthChallengeResponse{Response: fetch.AuthChallengeResponseResponseCancelAuth}
+			if auth.AuthChallenge.Source == fetch.AuthChallengeSourceProxy && proxyUser != nil {
+				password, _ := proxyUser.Password()
+				response = &fetch.AuthChallengeResponse{
+					Response: fetch.AuthChallengeResponseResponseProvideCredentials,
+					Username: proxyUser.Username(),
+					Password: password,
+				}
+			}
+			go fetch.ContinueWithAuth(auth.RequestID, response).Do(cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Target))
+			return
+		}
+		paused, ok := ev.(*fetch.EventRequestPaused)
+		if !ok {
+			return
+		}
+		go func() {
+			execCtx := cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Target)
+			requestURL := paused.Request.URL
+
+			switch {
+			case strings.HasPrefix(requestURL, captureURL):
+				var data []byte
+				for _, entry := range paused.Request.PostDataEntries {
+					decoded, _ := base64.StdEncoding.DecodeString(entry.Bytes)
+					data = append(data, decoded...)
+				}
+				select {
+				case captures <- captureEvent{Blocked: strings.Contains(requestURL, "blocked="), Data: string(data)}:
+				default:
+				}
+				fetch.FulfillRequest(paused.RequestID, 204).
+					WithResponseHeaders([]*fetch.HeaderEntry{{Name: "Access-Control-Allow-Origin", Value: "*"}}).
+					Do(execCtx)
+			case requestURL == pageURL:
+				fetch.FulfillRequest(paused.RequestID, 200).
+					WithResponseHeaders([]*fetch.HeaderEntry{{Name: "Content-Type", Value: "text/html; charset=utf-8"}}).
+					WithBody(base64.StdEncoding.EncodeToString([]byte(page))).
+					Do(execCtx)
+			default:
+				// The target itself, intercepted when it shares the finding's
+				// origin or for proxy credentials.
+				fetch.ContinueRequest(paused.RequestID).Do(execCtx)
+			}
+		}()
+	})
+
+	// Proxy challenges only reach requests Chrome intercepts, so with
+	// credentials every request is intercepted and the target's continued.
+	pattern := origin + "/*"
+	if proxyUser != nil {
+		pattern = "*"
+	}
+	actions := []chromedp.Action{
+		fetch.Enable().WithPatterns([]*fetch.RequestPattern{{URLPattern: pattern}}).WithHandleAuthRequests(proxyUser != nil),
+	}
+	secure := strings.HasPrefix(result.URL, "https://")
+	for _, pair := range strings.Split(cookiesFor(result.URL), ";") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		// Cross-site requests only carry SameSite=None cookies, which
+		// browsers accept over HTTPS only.
+		cookie := network.SetCookie(parts[0], parts[1]).WithURL(result.URL)
+		if secure {
+			cookie = cookie.WithSecure(true).WithSameSite(network.CookieSameSiteNone)
+		}
+		actions = append(actions, cookie)
+	}
+	actions = append(actions, chromedp.Navigate(pageURL))
+
+	if err := chromedp.Run(ctx, actions...); err != nil {
+		return captureEvent{}, false, err
+	}
+
+	select {
+	case event := <-captures:
+		return event, true, nil
+	case <-time.After(timeout):
+		return captureEvent{}, false, nil
+	case <-ctx.Done():
+		return captureEvent{}, false, ctx.Err()
+	}
+}
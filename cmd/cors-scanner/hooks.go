@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"time"
+)
+
+// hookRequest is what --pre-hook receives on stdin. The hook may print
+// the same object back with changed headers, e.g. to add a signature or a
+// fresh token; empty output leaves the request as it is.
+type hookRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers"`
+}
+
+// hookResponse is what --post-hook receives on stdin. A JSON object
+// printed by the hook is stored with the result as hook data.
+type hookResponse struct {
+	Request hookRequest `json:"request"`
+	Test    string      `json:"test"`
+	Vantage string      `json:"vantage,omitempty"`
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers"`
+}
+
+// runHook runs command through the shell with input as JSON on stdin and
+// returns its trimmed stdout.
+func runHook(command string, input interface{}) ([]byte, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.Timeout)*time.Second)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%w: %q: %v: %s", errHookFailed, command, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return bytes.TrimSpace(stdout.Bytes()), nil
+}
+
+// applyPreHook lets --pre-hook rewrite the headers of a request just
+// before it is sent.
+func applyPreHook(req *http.Request) error {
+	if config.PreHook == "" {
+		return nil
+	}
+	out, err := runHook(config.PreHook, hookRequest{Method: req.Method, URL: req.URL.String(), Headers: req.Header})
+	if err != nil || len(out) == 0 {
+		return err
+	}
+
+	var changed hookRequest
+	if err := json.Unmarshal(out, &changed); err != nil {
+		return fmt.Errorf("%w: pre-hook printed invalid JSON: %v", errHookFailed, err)
+	}
+	if changed.Headers != nil {
+		req.Header = changed.Headers
+	}
+	return nil
+}
+
+// runPostHook passes a response to --post-hook and returns the JSON
+// object it printed, if any.
+func runPostHook(resp *http.Response, test, vantageName string) (json.RawMessage, error) {
+	req := resp.Request
+	out, err := runHook(config.PostHook, hookResponse{
+		Request: hookRequest{Method: req.Method, URL: req.URL.String(), Headers: req.Header},
+		Test:    test,
+		Vantage: vantageName,
+		Status:  resp.StatusCode,
+		Headers: resp.Header,
+	})
+	if err != nil || len(out) == 0 {
+		return nil, err
+	}
+	if !json.Valid(out) || out[0] != '{' {
+		return nil, fmt.Errorf("post-hook printed invalid JSON object")
+	}
+	return json.RawMessage(out), nil
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// jiraClient opens tickets through the Jira REST API v2. Tokens of the form
+// user:apitoken use basic auth (Jira Cloud); anything else is sent as a
+// bearer personal access token (Jira Server/Data Center).
+type jiraClient struct {
+	baseURL string
+	project string
+	token   string
+	client  *http.Client
+}
+
+func newJiraClient(baseURL, project, token string) *jiraClient {
+	return &jiraClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		project: project,
+		token:   token,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (j *jiraClient) do(req *http.Request) (*http.Response, error) {
+	if strings.Contains(j.token, ":") {
+		req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(j.token)))
+	} else {
+		req.Header.Set("Authorization", "Bearer "+j.token)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("jira returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// exists reports whether a ticket labelled with the fingerprint is already
+// open in the project.
+func (j *jiraClient) exists(label string) (bool, error) {
+	jql := fmt.Sprintf("project = %q AND labels = %q", j.project, label)
+	req, err := http.NewRequest("GET", j.baseURL+"/rest/api/2/search?maxResults=1&fields=key&jql="+url.QueryEscape(jql), nil)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := j.do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	var search struct {
+		Total int `json:"total"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&search); err != nil {
+		return false, err
+	}
+	return search.Total > 0, nil
+}
+
+func (j *jiraClient) createIssue(summary, description string, labels []string) (string, error) {
+	payload := map[string]interface{}{
+		"fields": map[string]interface{}{
+			"project":     map[string]string{"key": j.project},
+			"issuetype":   map[string]string{"name": "Bug"},
+			"summary":     summary,
+			"description": description,
+			"labels":      labels,
+		},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest("POST", j.baseURL+"/rest/api/2/issue", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := j.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", err
+	}
+	return created.Key, nil
+}
+
+func (j *jiraClient) attach(issueKey, filename string, content []byte) error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return err
+	}
+	part.Write(content)
+	writer.Close()
+
+	req, err := http.NewRequest("POST", j.baseURL+"/rest/api/2/issue/"+issueKey+"/attachments", &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("X-Atlassian-Token", "no-check")
+
+	resp, err := j.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// findingEvidence describes the request and response behind a finding in
+// plain text suitable for ticket bodies.
+func findingEvidence(result ScanResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "URL: %s\n", result.URL)
+	fmt.Fprintf(&b, "Test: %s\n", result.Test)
+	fmt.Fprintf(&b, "Origin sent: %s\n", result.Origin)
+	if result.Status != 0 {
+		fmt.Fprintf(&b, "Response status: %d\n", result.Status)
+	}
+	if result.Vantage != "" {
+		fmt.Fprintf(&b, "Vantage: %s\n", result.Vantage)
+	}
+	fmt.Fprintf(&b, "Fingerprint: %s\n\n", fingerprint(result))
+
+	b.WriteString("Response headers:\n")
+	for _, h := range []struct{ name, value string }{
+		{"Access-Control-Allow-Origin", result.Headers.ACAO},
+		{"Access-Control-Allow-Credentials", result.Headers.ACAC},
+		{"Access-Control-Allow-Methods", result.Headers.ACAM},
+		{"Access-Control-Allow-Headers", result.Headers.ACAH},
+		{"Access-Control-Max-Age", result.Headers.ACMA},
+		{"Access-Control-Expose-Headers", result.Headers.ACEH},
+		{"Access-Control-Allow-Private-Network", result.Headers.ACAPN},
+	} {
+		if h.value != "" {
+			fmt.Fprintf(&b, "  %s: %s\n", h.name, h.value)
+		}
+	}
+
+	if p := result.CharProbe; p != nil {
+		b.WriteString("\nCharacters injected into the reflected origin:\n")
+		fmt.Fprintf(&b, "  Reflected: %s\n", formatChars(p.Reflected))
+		fmt.Fprintf(&b, "  Altered:   %s\n", formatChars(p.Altered))
+		fmt.Fprintf(&b, "  Rejected:  %s\n", formatChars(p.Rejected))
+	}
+
+	if len(result.ResponseHeaders) > 0 {
+		b.WriteString("\nAll response headers:\n")
+		for _, line := range sortedHeaderLines(result.ResponseHeaders) {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+
+	b.WriteString("\nRisks:\n")
+	for _, risk := range assessRisks(result) {
+		fmt.Fprintf(&b, "  [%s] %s\n", risk.Severity, risk.Message)
+	}
+	return b.String()
+}
+
+// pushJiraIssues opens one ticket per high or critical finding that has no
+// ticket yet, labelled with the finding fingerprint so later scans skip it.
+func pushJiraIssues() {
+	if config.JiraURL == "" {
+		return
+	}
+	if config.JiraProject == "" || config.JiraToken == "" {
+		fmt.Println("\n[!] Jira integration needs --jira-project and --jira-token.")
+		return
+	}
+
+	jira := newJiraClient(config.JiraURL, config.JiraProject, config.JiraToken)
+	handled := make(map[string]bool)
+	created := 0
+
+	for _, result := range results {
+		risks := assessRisks(result)
+		severity := maxSeverity(risks)
+		if severity < SeverityHigh {
+			continue
+		}
+
+		fp := fingerprint(result)
+		if handled[fp] {
+			continue
+		}
+		handled[fp] = true
+
+		label := "cors-" + fp
+		exists, err := jira.exists(label)
+		if err != nil {
+			fmt.Printf("[!] Jira search failed for %s: %v\n", result.URL, err)
+			emitError(errIntegration, result.URL, err)
+			continue
+		}
+		if exists {
+			continue
+		}
+
+		summary := fmt.Sprintf("[%s] CORS misconfiguration: %s", strings.ToUpper(severity.String()), result.URL)
+		description := "{noformat}\n" + findingEvidence(result) + "{noformat}"
+		key, err := jira.createIssue(summary, description, []string{"cors-scanner", label, "severity-" + severity.String()})
+		if err != nil {
+			fmt.Printf("[!] Jira issue creation failed for %s: %v\n", result.URL, err)
+			emitError(errIntegration, result.URL, err)
+			continue
+		}
+		if err := jira.attach(key, "cors-poc-"+fp+".html", []byte(buildPoC(result, ""))); err != nil {
+			fmt.Printf("[!] Attaching PoC to %s failed: %v\n", key, err)
+		}
+
+		created++
+		fmt.Printf("[+] Opened Jira issue %s for %s\n", key, result.URL)
+	}
+
+	fmt.Printf("[*] Jira: %d new issues opened.\n", created)
+}
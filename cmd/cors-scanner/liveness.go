@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// liveOrigin is what the liveness pass probes for a URL: its scheme and
+// host, so a host with many URLs is only probed once.
+func liveOrigin(targetURL string) string {
+	parsedURL, err := url.Parse(targetURL)
+	if err != nil || parsedURL.Host == "" {
+		return targetURL
+	}
+	return parsedURL.Scheme + "://" + parsedURL.Host
+}
+
+// probeLive sends one HEAD request with the short liveness timeout. Any
+// HTTP response counts as alive, whatever its status.
+func probeLive(ctx context.Context, client *http.Client, target string) error {
+	if err := waitToSend(ctx, target); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "HEAD", target+"/", nil)
+	if err != nil {
+		return err
+	}
+	userAgent := config.UserAgent
+	if userAgent == "" {
+		userAgent = getRandomUserAgent()
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// probeReachable resolves the host of target and opens a TCP connection
+// to its port, a fraction of the cost of an HTTP request.
+func probeReachable(ctx context.Context, target string) error {
+	parsedURL, err := url.Parse(target)
+	if err != nil {
+		return err
+	}
+	if err := waitToSend(ctx, target); err != nil {
+		return err
+	}
+	port := parsedURL.Port()
+	if port == "" {
+		port = "80"
+		if parsedURL.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(config.LiveTimeout)*time.Second)
+	defer cancel()
+	conn, err := dialContext(ctx, "tcp", net.JoinHostPort(parsedURL.Hostname(), port))
+	if err != nil {
+		return err
+	}
+	conn.Close()
+	return nil
+}
+
+// filterLive drops the URLs whose host does not answer a quick HEAD
+// request, or with --liveness-mode tcp accept a connection, so stale
+// entries in recon lists do not each sit through the full test battery
+// and its timeouts.
+func filterLive(ctx context.Context, urls []string) []string {
+	client := buildHTTPClient(config.Proxy)
+	client.Timeout = time.Duration(config.LiveTimeout) * time.Second
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	var targets []string
+	seen := make(map[string]bool)
+	for _, u := range urls {
+		target := liveOrigin(u)
+		if !seen[target] {
+			seen[target] = true
+			targets = append(targets, target)
+		}
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	alive := make(map[string]bool)
+	dead := make(map[string]int) // error code counts
+	targetChan := make(chan string, len(targets))
+
+	for i := 0; i < config.LiveThreads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range targetChan {
+				probe := func() error { return probeLive(ctx, client, target) }
+				if config.LiveMode == "tcp" {
+					probe = func() error { return probeReachable(ctx, target) }
+				}
+				if err := probe(); err != nil {
+					emitError(requestErrorCode(err), target, err)
+					mu.Lock()
+					dead[requestErrorCode(err)]++
+					mu.Unlock()
+					if config.Verbose {
+						fmt.Printf("Dropping unreachable host %s: %v\n", target, err)
+					}
+					continue
+				}
+				mu.Lock()
+				alive[target] = true
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, target := range targets {
+		targetChan <- target
+	}
+	close(targetChan)
+	wg.Wait()
+
+	var live []string
+	for _, u := range urls {
+		if alive[liveOrigin(u)] {
+			live = append(live, u)
+		}
+	}
+	fmt.Printf("[+] Liveness check: %d of %d hosts answered, %d of %d URLs kept.\n", len(alive), len(targets), len(live), len(urls))
+	if len(dead) > 0 {
+		var reasons []string
+		for code, count := range dead {
+			reasons = append(reasons, fmt.Sprintf("%s: %d", code, count))
+		}
+		sort.Strings(reasons)
+		fmt.Printf("[!] Skipped %d unreachable hosts (%s); list them with -v or --error-log.\n", len(targets)-len(alive), strings.Join(reasons, ", "))
+	}
+	return live
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// loginTokenFields are the JSON fields searched for a token when
+// --login-token names none.
+var loginTokenFields = []string{"access_token", "accessToken", "token", "id_token", "jwt"}
+
+// loginPlaceholder matches the ${NAME} placeholders filled from the
+// environment, so credentials need not be stored in the request file.
+var loginPlaceholder = regexp.MustCompile(`\$\{(\w+)\}`)
+
+func expandLoginTemplate(s string) string {
+	return loginPlaceholder.ReplaceAllStringFunc(s, func(m string) string {
+		return os.Getenv(m[2 : len(m)-1])
+	})
+}
+
+// login sends the --login-request or --login-url request before the scan.
+// The cookies it sets join the cookie jar, and a token in a JSON response
+// becomes the --bearer token unless credentials were given explicitly.
+func login(ctx context.Context) error {
+	req, err := newLoginRequest()
+	if err != nil {
+		return err
+	}
+	if err := checkScope(req.URL.String()); err != nil {
+		return fmt.Errorf("login request: %v", err)
+	}
+
+	if cookieJar == nil {
+		cookieJar, _ = cookiejar.New(nil)
+	}
+	client := buildHTTPClient(config.Proxy)
+	client.Jar = cookieJar
+	if err := waitToSend(ctx, req.URL.String()); err != nil {
+		return fmt.Errorf("login request: %v", err)
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("login request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("login request failed: HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return fmt.Errorf("cannot read login response: %v", err)
+	}
+
+	token := loginToken(body)
+	if token != "" && config.Bearer == "" && config.Basic == "" {
+		config.Bearer = token
+	}
+	if config.LoginToken != "" && token == "" {
+		return fmt.Errorf("login response has no %q token", config.LoginToken)
+	}
+
+	fmt.Printf("[+] Logged in via %s: %d cookies", req.URL.Host, len(cookieJar.Cookies(req.URL)))
+	if token != "" {
+		fmt.Print(", bearer token captured")
+	}
+	fmt.Println(".")
+	return nil
+}
+
+// newLoginRequest builds the login request from a raw request file, or
+// from --login-url and --login-body. A raw request with a relative
+// request line is sent to --login-url, or else to https://<Host>.
+func newLoginRequest() (*http.Request, error) {
+	if config.LoginRequest == "" {
+		body := expandLoginTemplate(config.LoginBody)
+		method := "GET"
+		if body != "" {
+			method = "POST"
+		}
+		req, err := http.NewRequest(method, config.LoginURL, strings.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if body != "" {
+			req.Header.Set("Content-Type", bodyContentType(body))
+		}
+		return req, nil
+	}
+
+	data, err := os.ReadFile(config.LoginRequest)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read login request: %v", err)
+	}
+	raw := strings.ReplaceAll(expandLoginTemplate(string(data)), "\r\n", "\n")
+	head, body, _ := strings.Cut(strings.TrimLeft(raw, "\n"), "\n\n")
+	parsed, err := http.ReadRequest(bufio.NewReader(strings.NewReader(head + "\n\n")))
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse login request %s: %v", config.LoginRequest, err)
+	}
+
+	target := parsed.URL
+	if !target.IsAbs() {
+		base := config.LoginURL
+		if base == "" {
+			base = "https://" + parsed.Host
+		}
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --login-url: %v", err)
+		}
+		target = baseURL.ResolveReference(parsed.URL)
+	}
+
+	req, err := http.NewRequest(parsed.Method, target.String(), bytes.NewReader([]byte(strings.TrimRight(body, "\n"))))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range parsed.Header {
+		if name == "Content-Length" || name == "Connection" {
+			continue
+		}
+		req.Header[name] = values
+	}
+	return req, nil
+}
+
+// loginToken returns the token in a JSON login response: the --login-token
+// field (a dotted path such as data.token), or else the first of the
+// usual token fields.
+func loginToken(body []byte) string {
+	var doc map[string]interface{}
+	if json.Unmarshal(body, &doc) != nil {
+		return ""
+	}
+
+	if config.LoginToken != "" {
+		var value interface{} = doc
+		for _, key := range strings.Split(config.LoginToken, ".") {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return ""
+			}
+			value = object[key]
+		}
+		token, _ := value.(string)
+		return token
+	}
+	for _, key := range loginTokenFields {
+		if token, ok := doc[key].(string); ok && token != "" {
+			return token
+		}
+	}
+	return ""
+}
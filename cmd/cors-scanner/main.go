@@ -0,0 +1,1059 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"cors-scanner/pkg/corscan"
+	"github.com/schollz/progressbar/v3"
+	"github.com/spf13/cobra"
+)
+
+type Config struct {
+	Verbose          bool
+	Proxy            string
+	ProxyAuth        string
+	CustomHeaders    []string
+	HeadersFile      string
+	Bearer           string
+	Basic            string
+	CookieJar        string
+	LoginRequest     string
+	LoginURL         string
+	LoginBody        string
+	LoginToken       string
+	Tests            []string
+	ExcludeTests     []string
+	OriginList       string
+	Cookies          []string
+	UserAgent        string
+	Referer          string
+	URLFile          []string
+	URL              string
+	CSVName          string
+	JSONFile         string
+	JSONLFile        string
+	Format           string
+	Threads          int
+	Timeout          int
+	FollowRedirects  bool
+	MaxRedirects     int
+	MatchStatus      []string
+	FilterStatus     []string
+	Vantages         []string
+	JiraURL          string
+	JiraProject      string
+	JiraToken        string
+	GitHubRepo       string
+	GitHubToken      string
+	DojoURL          string
+	DojoToken        string
+	DojoProduct      string
+	DojoEngagement   string
+	DojoTest         string
+	DojoExport       string
+	DradisFile       string
+	FaradayFile      string
+	SARIFFile        string
+	StatsdAddr       string
+	StatsdPrefix     string
+	HTMLFile         string
+	MarkdownFile     string
+	AcceptEncoding   string
+	CaptureHeaders   bool
+	UnixSocket       string
+	SourceIPs        []string
+	Pace             string
+	Delay            time.Duration
+	Jitter           time.Duration
+	Retries          int
+	ScanWindow       string
+	ScanWindowTZ     string
+	Queue            string
+	Resume           string
+	MaxScanTime      time.Duration
+	ConfigFile       string
+	ScopeFile        string
+	IncludeRegex     string
+	ExcludeRegex     string
+	Query            string
+	TraceFile        string
+	AuditLog         string
+	Browser          string
+	TLSFingerprint   string
+	HTTP2            bool
+	Resolver         string
+	DoH              string
+	Resolve          []string
+	Insecure         bool
+	TLSMinVersion    string
+	CACert           string
+	ClientCert       string
+	ClientKey        string
+	HTTP1            bool
+	PreHook          string
+	PostHook         string
+	Rules            []string
+	SignKey          string
+	ErrorLog         string
+	FailedFile       string
+	PrimeSession     bool
+	Method           string
+	Methods          []string
+	Data             string
+	Preflight        bool
+	Verify           bool
+	VerifyHeadless   bool
+	HeadlessWait     time.Duration
+	ChromePath       string
+	PreflightMethod  string
+	PreflightHeaders string
+	PrivateNetwork   bool
+	FuzzMethods      bool
+	HeaderReflection bool
+	Group            bool
+	Curl             bool
+	Ports            string
+	Cluster          bool
+	RequestDir       string
+	PoCDir           string
+	Liveness         bool
+	LiveTimeout      int
+	LiveThreads      int
+	LiveMode         string
+	NoDedupe         bool
+}
+
+type CORSHeaders = corscan.CORSHeaders
+
+// ScanResult is a corscan.Result with what the CLI adds to it: the
+// certificate, technology, cache and redirect details of the response,
+// and the output of verification, hooks, rules and clustering.
+type ScanResult struct {
+	corscan.Result
+	Verification string          `json:"verification,omitempty"` // confirmed or potential, with --verify
+	Headless     string          `json:"headless,omitempty"`     // exploited, blocked or no-report, with --verify-headless
+	FuzzMethod   string          `json:"fuzz_method,omitempty"`  // method a --fuzz-methods preflight asked about
+	FuzzHeader   string          `json:"fuzz_header,omitempty"`  // header a --header-reflection preflight asked about
+	CharProbe    *CharProbe      `json:"char_probe,omitempty"`
+	Certificate  *CertInfo       `json:"certificate,omitempty"`
+	Tech         *TechInfo       `json:"tech,omitempty"`
+	Cache        *CacheInfo      `json:"cache,omitempty"`
+	Redirects    []RedirectHop   `json:"redirects,omitempty"`
+	Hook         json.RawMessage `json:"hook,omitempty"`         // output of --post-hook
+	CustomRisks  []Risk          `json:"custom_risks,omitempty"` // from --rules
+	Signature    string          `json:"signature,omitempty"`    // response signature, with --cluster
+	Cluster      string          `json:"cluster,omitempty"`      // response cluster ID, with --cluster
+	Remediation  *Remediation    `json:"remediation,omitempty"`
+}
+
+var (
+	config     Config
+	results    []ScanResult
+	resultsMux sync.Mutex
+	bar        *progressbar.ProgressBar
+	// selectedTests are the --tests minus the --exclude-tests
+	selectedTests []string
+	// resultIndex holds the positions of each URL's results in results
+	resultIndex = make(map[string][]int)
+)
+
+func main() {
+	var rootCmd = &cobra.Command{
+		Use:   "cors-scanner [-]",
+		Short: "A multi-threaded CORS vulnerability scanner",
+		Long:  "A tool to help discover CORS misconfigurations by testing various origin header manipulations",
+		Run:   runScanner,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := loadConfigFile(cmd); err != nil {
+				return err
+			}
+			if err := applyBrowser(cmd); err != nil {
+				return err
+			}
+			if config.Proxy != "" {
+				if _, err := parseProxy(config.Proxy); err != nil {
+					return err
+				}
+			}
+			if err := parseTLSFingerprint(config.TLSFingerprint); err != nil {
+				return err
+			}
+			if err := setupResolver(); err != nil {
+				return err
+			}
+			if err := parseMethods(); err != nil {
+				return err
+			}
+			if err := parseStatusPatterns("match-status", config.MatchStatus); err != nil {
+				return err
+			}
+			if err := parseStatusPatterns("filter-status", config.FilterStatus); err != nil {
+				return err
+			}
+			switch config.LiveMode {
+			case "http":
+			case "tcp":
+				if config.Proxy != "" {
+					return fmt.Errorf("--liveness-mode tcp cannot be used with --proxy, which hides whether targets are reachable")
+				}
+			default:
+				return fmt.Errorf("invalid --liveness-mode %q: use http or tcp", config.LiveMode)
+			}
+			if err := parseResolve(config.Resolve); err != nil {
+				return err
+			}
+			if err := loadTLSSettings(); err != nil {
+				return err
+			}
+			if err := parseCustomHeaders(config.CustomHeaders); err != nil {
+				return err
+			}
+			tests, err := corscan.SelectTests(config.Tests, config.ExcludeTests)
+			if err != nil {
+				return err
+			}
+			selectedTests = tests
+			if config.OriginList != "" {
+				if err := loadOriginList(config.OriginList); err != nil {
+					return err
+				}
+			}
+			if config.CookieJar != "" {
+				if err := loadCookieJar(config.CookieJar); err != nil {
+					return err
+				}
+			}
+			if config.Bearer != "" && config.Basic != "" {
+				return fmt.Errorf("please specify either --bearer or --basic, not both")
+			}
+			if config.Basic != "" && !strings.Contains(config.Basic, ":") {
+				return fmt.Errorf("invalid --basic, expected user:pass")
+			}
+			if config.HTTP2 && config.HTTP1 {
+				return fmt.Errorf("please specify either --http2 or --http1, not both")
+			}
+			if config.HTTP2 && tlsHello != nil {
+				return fmt.Errorf("--http2 cannot be combined with --tls-fingerprint, which only offers HTTP/1.1")
+			}
+			if err := loadSigningKey(config.SignKey); err != nil {
+				return err
+			}
+			if includeRegex, err = compileScopeRegex("include-regex", config.IncludeRegex); err != nil {
+				return err
+			}
+			if excludeRegex, err = compileScopeRegex("exclude-regex", config.ExcludeRegex); err != nil {
+				return err
+			}
+			if config.ScopeFile == "" {
+				return nil
+			}
+			scope, err = loadScope(config.ScopeFile)
+			return err
+		},
+	}
+
+	rootCmd.PersistentFlags().BoolVarP(&config.Verbose, "verbose", "v", false, "increase output verbosity")
+	rootCmd.PersistentFlags().StringVar(&config.ConfigFile, "config", "", "specify a YAML file of flag settings, e.g. one per engagement; command-line flags win")
+	rootCmd.PersistentFlags().StringVar(&config.Proxy, "proxy", "", "specify a proxy to use: 127.0.0.1:8080, http://, https://, socks5:// or socks5h://")
+	rootCmd.PersistentFlags().StringVar(&config.ProxyAuth, "proxy-auth", "", "specify proxy credentials as user:pass, for proxies given without their own")
+	rootCmd.PersistentFlags().StringArrayVar(&config.CustomHeaders, "custom-header", []string{}, "specify a custom header as \"Name: value\" or Name~~~value (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&config.CookieJar, "cookie-jar", "", "specify a cookies.txt, browser-exported JSON or HAR file; cookies are sent where their domain, path and secure flag match")
+	rootCmd.PersistentFlags().StringSliceVar(&config.Tests, "tests", []string{}, "specify the tests to run, comma-separated (default all: "+strings.Join(corscan.Tests, ", ")+")")
+	rootCmd.PersistentFlags().StringSliceVar(&config.ExcludeTests, "exclude-tests", []string{}, "specify tests to skip, comma-separated")
+	rootCmd.PersistentFlags().StringVar(&config.OriginList, "origin-list", "", "specify a file of origins (partner, staging, attacker domains) to send to every URL as well")
+	rootCmd.Flags().StringVar(&config.LoginRequest, "login-request", "", "specify a raw HTTP login request to send before scanning; its cookies and token are used for every test request")
+	rootCmd.Flags().StringVar(&config.LoginURL, "login-url", "", "specify a login URL to request before scanning, or the base URL of a relative --login-request")
+	rootCmd.Flags().StringVar(&config.LoginBody, "login-body", "", "specify the body to POST to --login-url; ${NAME} is replaced from the environment")
+	rootCmd.Flags().StringVar(&config.LoginToken, "login-token", "", "specify the JSON field of the login response holding a bearer token, e.g. data.token")
+	rootCmd.PersistentFlags().StringVar(&config.Bearer, "bearer", "", "specify a bearer token to send as Authorization: Bearer <token>")
+	rootCmd.PersistentFlags().StringVar(&config.Basic, "basic", "", "specify user:pass to send as HTTP Basic authorization")
+	rootCmd.PersistentFlags().StringVar(&config.HeadersFile, "headers-file", "", "specify a file of \"Name: value\" lines, e.g. a request copied from Burp, to send with every request")
+	rootCmd.PersistentFlags().StringSliceVarP(&config.Cookies, "cookies", "c", []string{}, "specify domain(s) and cookie(s) data delimited with ~~~")
+	rootCmd.PersistentFlags().StringVar(&config.UserAgent, "useragent", "", "specify a User Agent string to use")
+	rootCmd.PersistentFlags().StringVarP(&config.Referer, "referer", "r", "", "specify a referer string to use")
+	rootCmd.Flags().StringArrayVar(&config.URLFile, "url-file", []string{}, "specify a file containing URLs, or a glob such as targets/*.txt (repeatable)")
+	rootCmd.Flags().StringVarP(&config.URL, "url", "u", "", "specify a single URL")
+	rootCmd.Flags().StringVar(&config.CSVName, "csv-name", "", "specify a CSV file name")
+	rootCmd.Flags().StringVar(&config.JSONFile, "json", "", "specify a JSON file to write the full results to")
+	rootCmd.Flags().StringVar(&config.JSONLFile, "jsonl", "", "specify a JSONL file each result is appended to as soon as it is found")
+	rootCmd.Flags().StringVar(&config.Format, "format", "csv", "specify the default results format: csv or json")
+	rootCmd.Flags().BoolVar(&config.CaptureHeaders, "capture-headers", false, "store the complete response header set with each result")
+	rootCmd.Flags().BoolVar(&config.PrimeSession, "prime-session", false, "send a plain GET to each target first and reuse the cookies it sets for the origin tests")
+	rootCmd.Flags().BoolVar(&config.Verify, "verify", false, "re-send every candidate finding with a Cookie header and mark it confirmed or potential")
+	rootCmd.Flags().BoolVar(&config.VerifyHeadless, "verify-headless", false, "load the PoC of every exploitable finding in headless Chrome and record whether it read the target")
+	rootCmd.Flags().DurationVar(&config.HeadlessWait, "headless-wait", 15*time.Second, "specify how long --verify-headless waits for a PoC to report back")
+	rootCmd.Flags().StringVar(&config.ChromePath, "chrome-path", "", "specify the Chrome or Chromium binary for --verify-headless (default: search PATH)")
+	rootCmd.Flags().StringVar(&config.Method, "method", "", "specify the request method of the tests (default GET, or POST with --data)")
+	rootCmd.Flags().StringSliceVar(&config.Methods, "methods", []string{}, "specify several request methods to send every test with, comma-separated, e.g. GET,POST,DELETE")
+	rootCmd.PersistentFlags().StringVar(&config.Data, "data", "", "specify a request body to send with methods other than GET, HEAD and OPTIONS")
+	rootCmd.Flags().BoolVar(&config.Preflight, "preflight", false, "also send every test as an OPTIONS preflight and compare it with the simple request")
+	rootCmd.PersistentFlags().StringVar(&config.PreflightMethod, "preflight-method", "GET", "specify the Access-Control-Request-Method of preflights")
+	rootCmd.Flags().BoolVar(&config.FuzzMethods, "fuzz-methods", false, "send preflights asking for PUT, DELETE, PATCH and TRACE from a foreign origin and report the methods allowed")
+	rootCmd.Flags().BoolVar(&config.HeaderReflection, "header-reflection", false, "send a preflight asking for a random custom header and report servers that echo it in Access-Control-Allow-Headers")
+	rootCmd.Flags().BoolVar(&config.PrivateNetwork, "private-network", false, "send preflights with Access-Control-Request-Private-Network: true to test Private Network Access (implies --preflight)")
+	rootCmd.PersistentFlags().StringVar(&config.PreflightHeaders, "preflight-headers", "", "specify the Access-Control-Request-Headers of preflights, e.g. \"Authorization, Content-Type\"")
+	rootCmd.Flags().StringVar(&config.HTMLFile, "html", "", "specify an HTML report file to write")
+	rootCmd.Flags().StringVar(&config.RequestDir, "export-requests", "", "specify a directory to write each finding's raw request to, for Burp Repeater and .http clients")
+	rootCmd.Flags().StringVar(&config.PoCDir, "poc-dir", "", "specify a directory to write a ready-to-host HTML PoC page for each exploitable finding to")
+	rootCmd.Flags().StringVar(&config.MarkdownFile, "markdown", "", "specify a Markdown report file to write")
+	rootCmd.Flags().IntVarP(&config.Threads, "threads", "t", 10, "specify number of threads")
+	rootCmd.Flags().StringVar(&config.Ports, "ports", "", "specify ports to probe on each host, e.g. 80,443,8080,8443,3000; every answering port is scanned")
+	rootCmd.Flags().BoolVar(&config.Cluster, "cluster", false, "cluster URLs with near-identical responses (shared backends, wildcard vhosts, parked domains) and annotate their findings")
+	rootCmd.Flags().BoolVar(&config.Liveness, "liveness", false, "drop hosts that do not answer a quick HEAD request before scanning")
+	rootCmd.Flags().IntVar(&config.LiveTimeout, "liveness-timeout", 3, "specify the liveness check timeout in seconds")
+	rootCmd.Flags().IntVar(&config.LiveThreads, "liveness-threads", 100, "specify number of threads for the liveness check")
+	rootCmd.Flags().BoolVar(&config.NoDedupe, "no-dedupe", false, "scan input URLs exactly as given instead of normalising them and dropping duplicates")
+	rootCmd.Flags().StringVar(&config.LiveMode, "liveness-mode", "http", "specify how the liveness check probes hosts: http (a HEAD request) or tcp (DNS lookup and TCP connect only)")
+	rootCmd.Flags().StringVar(&config.ScanWindow, "scan-window", "", "specify the daily testing window, e.g. 22:00-06:00; requests pause outside it")
+	rootCmd.Flags().StringVar(&config.ScanWindowTZ, "scan-window-tz", "", "specify the timezone for --scan-window (default local time)")
+	rootCmd.Flags().StringVar(&config.Queue, "queue", "", "specify a persistent queue file; targets are added to it and worked until it is empty")
+	rootCmd.Flags().StringVar(&config.Resume, "resume", "", "specify a state file recording completed URLs; a restarted scan skips them and keeps their results")
+	rootCmd.Flags().DurationVar(&config.MaxScanTime, "max-scan-time", 0, "specify the longest the scan may run, e.g. 30m; the results collected by then are still written")
+	rootCmd.Flags().DurationVar(&config.Delay, "delay", 0, "specify the delay between requests across all threads, e.g. 2s")
+	rootCmd.Flags().DurationVar(&config.Jitter, "jitter", 0, "specify a random extra delay of up to this much per request, e.g. 1s")
+	rootCmd.Flags().IntVar(&config.Retries, "retries", 0, "specify how often a failed request is retried, with exponential backoff from 1s")
+	rootCmd.Flags().StringVar(&config.FailedFile, "failed-urls", "", "specify a file to write the URLs whose requests failed after every retry to")
+	rootCmd.Flags().StringVar(&config.Pace, "pace", "", "specify a pacing profile: aggressive, normal or paranoid")
+	rootCmd.PersistentFlags().BoolVar(&config.Curl, "curl", false, "show the curl command that reproduces each result in output and reports")
+	rootCmd.PersistentFlags().BoolVar(&config.Group, "group", false, "collapse the results of all tests against a URL into one finding in output and reports")
+	rootCmd.PersistentFlags().StringVar(&config.Browser, "browser", "", "specify a browser header profile to emulate: chrome, firefox or safari")
+	rootCmd.PersistentFlags().StringVar(&config.TLSFingerprint, "tls-fingerprint", "", "specify a browser TLS ClientHello to mimic: chrome, firefox or safari")
+	rootCmd.PersistentFlags().BoolVar(&config.HTTP2, "http2", false, "negotiate HTTP/2 with HTTPS targets that support it")
+	rootCmd.PersistentFlags().BoolVar(&config.HTTP1, "http1", false, "only ever speak HTTP/1.1")
+	rootCmd.PersistentFlags().StringVar(&config.Resolver, "resolver", "", "specify a DNS server to resolve targets with instead of the system resolver, e.g. 10.0.0.2:53")
+	rootCmd.PersistentFlags().StringVar(&config.DoH, "doh", "", "specify a DNS-over-HTTPS URL to resolve targets with, e.g. https://1.1.1.1/dns-query")
+	rootCmd.PersistentFlags().BoolVarP(&config.Insecure, "insecure", "k", false, "skip TLS certificate verification; certificate problems are still recorded in the results")
+	rootCmd.PersistentFlags().StringVar(&config.TLSMinVersion, "tls-min-version", "", "specify the lowest TLS version to accept: 1.0, 1.1, 1.2 or 1.3")
+	rootCmd.PersistentFlags().StringVar(&config.CACert, "ca-cert", "", "specify a PEM file of CA certificates to trust in addition to the system roots")
+	rootCmd.PersistentFlags().StringVar(&config.ClientCert, "client-cert", "", "specify a PEM client certificate for targets that require mutual TLS")
+	rootCmd.PersistentFlags().StringVar(&config.ClientKey, "client-key", "", "specify the PEM private key of --client-cert")
+	rootCmd.PersistentFlags().StringArrayVar(&config.Resolve, "resolve", []string{}, "specify host:port:addr to connect to addr for host:port, like curl (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&config.AcceptEncoding, "accept-encoding", "gzip, br", "specify the Accept-Encoding header to send (empty to omit)")
+	rootCmd.PersistentFlags().StringVar(&config.UnixSocket, "unix-socket", "", "specify a Unix domain socket to send all requests to")
+	rootCmd.PersistentFlags().StringSliceVar(&config.SourceIPs, "source-ip", []string{}, "specify local address(es) to send requests from, rotated per connection")
+	rootCmd.PersistentFlags().StringVar(&config.Query, "query", "keep", "specify how URLs differing only in query parameters are handled: keep, strip or group")
+	rootCmd.PersistentFlags().StringVar(&config.TraceFile, "trace", "", "specify a file to dump every raw HTTP request and response to, for debugging")
+	rootCmd.PersistentFlags().StringVar(&config.AuditLog, "audit-log", "", "specify a file to append a timestamped JSON line to for every request sent")
+	rootCmd.PersistentFlags().StringVar(&config.PreHook, "pre-hook", "", "specify a command that receives each request as JSON on stdin and may print it back with changed headers")
+	rootCmd.Flags().StringArrayVar(&config.Rules, "rules", []string{}, "specify a Starlark file defining check(result) for custom findings (repeatable)")
+	rootCmd.Flags().StringVar(&config.PostHook, "post-hook", "", "specify a command that receives each response as JSON on stdin; a JSON object it prints is stored with the result")
+	rootCmd.PersistentFlags().StringVar(&config.SignKey, "sign-key", "", "specify a key file to HMAC-sign every written results/report file (<file>.sig)")
+	rootCmd.PersistentFlags().StringVar(&config.ErrorLog, "error-log", "", "specify a file (- for stderr) to write non-fatal errors to as JSON lines with error codes")
+	rootCmd.PersistentFlags().StringVar(&config.ScopeFile, "scope", "", "specify a scope file of in-scope and !out-of-scope domains/CIDRs; other requests are refused")
+	rootCmd.PersistentFlags().StringVar(&config.ScopeFile, "scope-file", "", "same as --scope")
+	rootCmd.PersistentFlags().StringVar(&config.IncludeRegex, "include-regex", "", "specify a regular expression URLs must match; other requests are refused")
+	rootCmd.PersistentFlags().StringVar(&config.ExcludeRegex, "exclude-regex", "", "specify a regular expression of URLs to refuse requests to")
+	rootCmd.PersistentFlags().IntVar(&config.Timeout, "timeout", 10, "specify connection timeout in seconds")
+	rootCmd.PersistentFlags().BoolVar(&config.FollowRedirects, "follow-redirects", true, "follow redirects and test the final response; with --follow-redirects=false the redirect itself is tested")
+	rootCmd.PersistentFlags().IntVar(&config.MaxRedirects, "max-redirects", 10, "specify the most redirects to follow")
+	rootCmd.Flags().StringSliceVar(&config.MatchStatus, "match-status", []string{}, "only keep results with these response statuses, comma-separated codes or classes, e.g. 200,401,403 or 2xx")
+	rootCmd.Flags().StringSliceVar(&config.FilterStatus, "filter-status", []string{}, "drop results with these response statuses, e.g. 404,5xx")
+	rootCmd.PersistentFlags().StringArrayVar(&config.Vantages, "vantage", []string{}, "specify a named vantage point as name=proxyurl (repeatable)")
+	rootCmd.Flags().StringVar(&config.JiraURL, "jira-url", "", "specify a Jira base URL to open tickets for new high/critical findings")
+	rootCmd.Flags().StringVar(&config.JiraProject, "jira-project", "", "specify the Jira project key for new tickets")
+	rootCmd.Flags().StringVar(&config.JiraToken, "jira-token", "", "specify a Jira API token (user:token for Jira Cloud)")
+	rootCmd.Flags().StringVar(&config.GitHubRepo, "github-repo", "", "specify an owner/repo to file GitHub issues for new findings")
+	rootCmd.Flags().StringVar(&config.GitHubToken, "github-token", "", "specify a GitHub token (defaults to $GITHUB_TOKEN)")
+	rootCmd.Flags().StringVar(&config.DojoURL, "dojo-url", "", "specify a DefectDojo base URL to push findings to")
+	rootCmd.Flags().StringVar(&config.DojoToken, "dojo-token", "", "specify a DefectDojo API v2 key")
+	rootCmd.Flags().StringVar(&config.DojoProduct, "dojo-product", "", "specify the DefectDojo product name")
+	rootCmd.Flags().StringVar(&config.DojoEngagement, "dojo-engagement", "", "specify the DefectDojo engagement name (created if missing)")
+	rootCmd.Flags().StringVar(&config.DojoTest, "dojo-test", "CORS Scanner", "specify the DefectDojo test title")
+	rootCmd.Flags().StringVar(&config.DojoExport, "dojo-export", "", "specify a file to write DefectDojo Generic Findings Import JSON to")
+	rootCmd.Flags().StringVar(&config.DradisFile, "dradis", "", "specify a file to write a Dradis project template (XML) to")
+	rootCmd.Flags().StringVar(&config.FaradayFile, "faraday", "", "specify a file to write a Faraday JSON report to")
+	rootCmd.Flags().StringVar(&config.SARIFFile, "sarif", "", "specify a file to write a SARIF 2.1.0 log to")
+	rootCmd.Flags().StringVar(&config.StatsdAddr, "statsd", "", "specify a StatsD/DogStatsD address (host:port) to emit scan metrics to")
+	rootCmd.Flags().StringVar(&config.StatsdPrefix, "statsd-prefix", "cors_scanner", "specify the metric name prefix for --statsd")
+
+	rootCmd.AddCommand(newVerifyCmd())
+	rootCmd.AddCommand(newExploitCmd())
+	rootCmd.AddCommand(newReportCmd())
+	rootCmd.AddCommand(newMergeCmd())
+	rootCmd.AddCommand(newStatsCmd())
+	rootCmd.AddCommand(newQueueCmd())
+	rootCmd.AddCommand(newVerifySigCmd())
+	rootCmd.AddCommand(newTrendCmd())
+
+	if err := rootCmd.Execute(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func runScanner(cmd *cobra.Command, args []string) {
+	if err := applyPace(cmd); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, arg := range args {
+		if arg != "-" {
+			log.Fatalf("unexpected argument %q (use -u, --url-file or - for stdin)", arg)
+		}
+		if !containsString(config.URLFile, "-") {
+			config.URLFile = append(config.URLFile, "-")
+		}
+	}
+	
+	switch strings.ToLower(config.Format) {
+	case "csv", "json":
+	default:
+		log.Fatalf("unknown format %q (use csv or json)", config.Format)
+	}
+	
+	printBanner()
+	
+	urls, err := parseURLs()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	vantages, err = parseVantages(config.Vantages)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if config.ScanWindow != "" {
+		window, err = parseScanWindow(config.ScanWindow, config.ScanWindowTZ)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if err := loadRules(config.Rules); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, addr := range config.SourceIPs {
+		if err := validateSourceIP(addr); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if config.StatsdAddr != "" {
+		metrics, err = newStatsdClient(config.StatsdAddr, config.StatsdPrefix)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer metrics.Close()
+	}
+
+	// The pre-passes below send requests too, so they are paced and bound
+	// by the scan window and deadline like the tests.
+	ctx, cancel := scanContext()
+	defer cancel()
+	handleInterrupts()
+	
+	if config.Ports != "" && len(urls) > 0 {
+		if urls, err = expandPorts(ctx, urls, config.Ports); err != nil {
+			log.Fatal(err)
+		}
+	}
+	
+	if config.Resume != "" {
+		if config.Queue != "" {
+			log.Fatal("--resume cannot be combined with --queue, which keeps its own progress")
+		}
+		if urls, err = resumeTargets(urls); err != nil {
+			log.Fatal(err)
+		}
+	}
+	
+	if config.Liveness && len(urls) > 0 {
+		urls = filterLive(ctx, urls)
+	}
+	
+	if config.LoginRequest != "" || config.LoginURL != "" {
+		if err := login(ctx); err != nil {
+			log.Fatal(err)
+		}
+	} else if config.LoginBody != "" || config.LoginToken != "" {
+		log.Fatal("--login-body and --login-token need --login-request or --login-url")
+	}
+	
+	if config.Queue != "" {
+		q, err := openQueue(config.Queue)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if _, err := q.Add(urls); err != nil {
+			log.Fatal(err)
+		}
+		// Other invocations can grow the queue while it is worked, so the
+		// total is unknown.
+		if !config.Verbose {
+			bar = progressbar.Default(-1)
+		}
+		scanQueue(ctx, q)
+	} else {
+		if !config.Verbose {
+			bar = progressbar.Default(int64(len(urls)))
+		}
+		scanURLs(ctx, urls)
+	}
+	
+	// Clear progress bar before showing results
+	if !config.Verbose && bar != nil {
+		fmt.Print("\n")
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		fmt.Printf("[!] Scan stopped after --max-scan-time %s: the results are partial.\n", config.MaxScanTime)
+	} else if scanStopped(ctx) {
+		fmt.Println("[!] Scan interrupted: the results are partial.")
+	}
+	if config.Verify {
+		printVerification(results)
+	}
+	var clusters []*responseCluster
+	if config.Cluster {
+		clusters = clusterResults(results)
+	}
+	printResults()
+	printClusters(clusters)
+	printVantageDiff()
+	printMethodDiscrepancies()
+	printMethodFuzzes()
+	printHeaderReflections()
+	printAllowedListOrigins()
+	printThrottleSummary()
+	printFailures()
+	if !strings.EqualFold(config.Format, "json") || config.CSVName != "" {
+		writeCSV()
+	}
+	if strings.EqualFold(config.Format, "json") && config.JSONFile == "" {
+		config.JSONFile = "CORS_Results-" + time.Now().Format("02Jan2006150405") + ".json"
+	}
+	writeJSON()
+	writeReports()
+	writeRequestExports()
+	writePoCFiles()
+	writeFailedURLs()
+	writeDojoExport()
+	writeReportingPlatformExports()
+	pushJiraIssues()
+	pushGitHubIssues()
+	pushDefectDojo()
+}
+
+func printBanner() {
+	banner := "CORS Scanner v1.0"
+	author := "Habib0x"
+	fmt.Println(strings.Repeat("=", len(banner)))
+	fmt.Println(banner)
+	fmt.Println(author)
+	fmt.Println(strings.Repeat("=", len(banner)))
+	fmt.Println()
+	
+	if config.Verbose {
+		fmt.Printf("Threads: %d\n", config.Threads)
+		fmt.Printf("Timeout: %d\n", config.Timeout)
+		if config.Pace != "" {
+			fmt.Printf("Pace: %s (delay %s, jitter %s, retries %d)\n", config.Pace, config.Delay, config.Jitter, config.Retries)
+		}
+		if config.Proxy != "" {
+			fmt.Printf("Proxy: %s\n", redactProxy(config.Proxy))
+		}
+		if config.Resolver != "" {
+			fmt.Printf("Resolver: %s\n", config.Resolver)
+		}
+		if config.DoH != "" {
+			fmt.Printf("Resolver: %s\n", config.DoH)
+		}
+		fmt.Println()
+	}
+	
+	time.Sleep(1 * time.Second)
+}
+
+func parseURLs() ([]string, error) {
+	if config.URL == "" && len(config.URLFile) == 0 {
+		// An existing queue can be worked without adding to it.
+		if config.Queue != "" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("please specify a URL (-u) or an input file containing URLs (--url-file)")
+	}
+	
+	if config.URL != "" && len(config.URLFile) > 0 {
+		return nil, fmt.Errorf("please specify either a URL or a file, not both")
+	}
+	
+	var urls []string
+	
+	if len(config.URLFile) > 0 {
+		paths, err := expandURLFiles(config.URLFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			fromFile, err := loadURLFile(path)
+			if err != nil {
+				return nil, err
+			}
+			urls = append(urls, fromFile...)
+		}
+	} else {
+		if !strings.HasPrefix(config.URL, "http") {
+			return nil, fmt.Errorf("please specify a URL in the format proto://address:port")
+		}
+		urls = []string{config.URL}
+	}
+	
+	return prepareTargets(urls)
+}
+
+// expandURLFiles expands the globs among --url-file values, such as
+// targets/*.txt, and drops files named more than once.
+func expandURLFiles(patterns []string) ([]string, error) {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches := []string{pattern}
+		if pattern != "-" && strings.ContainsAny(pattern, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(pattern); err != nil {
+				return nil, fmt.Errorf("invalid --url-file pattern %q: %v", pattern, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match --url-file %s", pattern)
+			}
+		}
+		for _, path := range matches {
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths, nil
+}
+
+// loadURLFile reads the targets of one --url-file: structured target
+// options, Nmap XML, or one URL per line.
+func loadURLFile(path string) ([]string, error) {
+	if isStructuredInput(path) {
+		return loadTargetFile(path)
+	}
+	if isNmapXML(path) {
+		return loadNmapXML(path)
+	}
+	
+	// "-" reads the URLs from stdin, for pipelines such as
+	// subfinder | httpx | cors-scanner -
+	input := os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open file: %v", err)
+		}
+		defer file.Close()
+		input = file
+	}
+	
+	var urls []string
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" {
+			urls = append(urls, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading file: %v", err)
+	}
+	return urls, nil
+}
+
+// prepareTargets normalises and dedupes a list of input URLs, and applies
+// the --query strategy and the scope to it.
+func prepareTargets(urls []string) ([]string, error) {
+	urls, err := applyQueryStrategy(dedupeURLs(urls), config.Query)
+	if err != nil {
+		return nil, err
+	}
+	return inScopeURLs(urls), nil
+}
+
+func scanURLs(ctx context.Context, urls []string) {
+	engine := newEngine()
+	var wg sync.WaitGroup
+	urlChan := make(chan string, len(urls))
+	
+	// Start workers
+	for i := 0; i < config.Threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for url := range urlChan {
+				if scanStopped(ctx) {
+					continue
+				}
+				testCORSPolicy(ctx, engine, url)
+				// A URL cut off by the deadline, or with failed requests, is
+				// scanned again on resume
+				if ctx.Err() == nil && !hasFailures(url) {
+					checkpointURL(url)
+				}
+				metrics.Incr("urls.completed")
+				if !config.Verbose && bar != nil {
+					bar.Add(1)
+				}
+			}
+		}()
+	}
+	
+	// Send URLs to workers
+	for _, url := range urls {
+		urlChan <- url
+	}
+	close(urlChan)
+	
+	wg.Wait()
+}
+
+func getRandomUserAgent() string {
+	userAgents := []string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/42.0.2311.135 Safari/537.36 Edge/12.246",
+		"Mozilla/5.0 (Windows NT 10.0; WOW64; rv:40.0) Gecko/20100101 Firefox/43.0",
+		"Mozilla/5.0 (Windows NT 6.1; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/63.0.3239.84 Safari/537.36",
+		"Mozilla/5.0 (X11; Linux i686; rv:30.0) Gecko/20100101 Firefox/42.0",
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_10_2) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/40.0.2214.38 Safari/537.36",
+		"Mozilla/4.0 (compatible; MSIE 6.0; Windows NT 5.0)",
+	}
+	return userAgents[rand.Intn(len(userAgents))]
+}
+
+func buildHTTPClient(proxy string) *http.Client {
+	transport := &http.Transport{
+		TLSClientConfig: tlsClientConfig(),
+		DialContext:     dialContext,
+	}
+	// Requests tunnelled through a proxy still use Go's own handshake.
+	if tlsHello != nil {
+		transport.DialTLSContext = dialTLSContext
+	}
+	
+	// With a custom dialer Go only negotiates HTTP/2 when asked to
+	switch {
+	case config.HTTP2:
+		transport.ForceAttemptHTTP2 = true
+	case config.HTTP1:
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	
+	// A proxy would be dialled over the socket too, so it makes no sense
+	// to combine the two.
+	if proxy != "" && config.UnixSocket == "" {
+		proxyURL, err := parseProxy(proxy)
+		if err == nil {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+	
+	var roundTripper http.RoundTripper = transport
+	if config.TraceFile != "" {
+		roundTripper = &tracingTransport{next: roundTripper}
+	}
+	if config.AuditLog != "" {
+		roundTripper = &auditTransport{next: roundTripper}
+	}
+	
+	return &http.Client{
+		Transport:     roundTripper,
+		Timeout:       time.Duration(config.Timeout) * time.Second,
+		CheckRedirect: checkRedirect,
+	}
+}
+
+func makeRequest(ctx context.Context, client *http.Client, r corscan.Request) (*http.Response, error) {
+	req, err := newScanRequest(r.Method, r.URL, r.Origin)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range r.Header {
+		req.Header[name] = values
+	}
+	return client.Do(req.WithContext(ctx))
+}
+
+// newScanRequest builds a test request with every configured header,
+// cookie and hook applied, exactly as it is sent.
+func newScanRequest(method, targetURL, origin string) (*http.Request, error) {
+	if err := checkScope(targetURL); err != nil {
+		return nil, err
+	}
+	
+	body := requestBody(method)
+	req, err := http.NewRequest(method, targetURL, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", bodyContentType(body))
+	}
+	
+	// An OPTIONS request is only treated as a preflight when it names the
+	// method it is asking about
+	if method == "OPTIONS" {
+		for _, h := range preflightHeaders() {
+			req.Header.Set(h[0], h[1])
+		}
+	}
+	
+	// Set User-Agent
+	userAgent := config.UserAgent
+	if userAgent == "" {
+		userAgent = getRandomUserAgent()
+	}
+	req.Header.Set("User-Agent", userAgent)
+	
+	// Set the --browser profile headers
+	for _, h := range browserHeaders {
+		req.Header.Set(h[0], h[1])
+	}
+	
+	// Set Origin; session priming sends none
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	
+	// Set Accept-Encoding; gateways sometimes route compressed and
+	// uncompressed requests to different backends
+	if config.AcceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", config.AcceptEncoding)
+	}
+	
+	// Set Referer if specified
+	if config.Referer != "" {
+		req.Header.Set("Referer", config.Referer)
+	}
+	
+	// Set --bearer or --basic credentials; custom headers and per-target
+	// tokens override them
+	if auth := credentials(); auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	
+	// Set custom headers if specified
+	for _, h := range customHeaders {
+		req.Header.Set(h[0], h[1])
+	}
+	
+	// Set per-target overrides; their cookies replace --cookies
+	ownCookies := applyTargetOptions(req, targetURL)
+	
+	// Set cookies if specified
+	for _, cookieStr := range config.Cookies {
+		if ownCookies {
+			break
+		}
+		parts := strings.Split(cookieStr, "~~~")
+		if len(parts) == 2 {
+			domain := parts[0]
+			cookies := parts[1]
+			
+			parsedURL, err := url.Parse(targetURL)
+			if err == nil && strings.Contains(domain, parsedURL.Host) {
+				cookiePairs := strings.Split(cookies, ";")
+				for _, pair := range cookiePairs {
+					cookieParts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+					if len(cookieParts) == 2 {
+						cookie := &http.Cookie{
+							Name:  cookieParts[0],
+							Value: cookieParts[1],
+						}
+						req.AddCookie(cookie)
+					}
+				}
+			}
+		}
+	}
+	
+	// Set the --cookie-jar cookies that match the URL
+	if cookieJar != nil && !ownCookies {
+		addJarCookies(req, cookieJar)
+	}
+	
+	// Set the cookies collected by --prime-session
+	if config.PrimeSession {
+		addJarCookies(req, sessionJar)
+	}
+	
+	// Let --pre-hook sign or otherwise adjust the final request
+	if err := applyPreHook(req); err != nil {
+		return nil, err
+	}
+	
+	return req, nil
+}
+
+func printResults() {
+	if len(results) == 0 {
+		fmt.Println("\n[*] No CORS headers found in any responses.")
+		return
+	}
+
+	fmt.Println(strings.Repeat("=", 70))
+	fmt.Printf("CORS SCAN RESULTS - Found %d CORS configurations\n", len(results))
+	fmt.Println(strings.Repeat("=", 70))
+
+	if config.Group {
+		groups := groupByURL(results)
+		printGroupedResults(groups, clusterSizes(results))
+		fmt.Println("\n" + strings.Repeat("-", 70))
+		fmt.Printf("Summary: %d total CORS configurations found on %d URLs\n", len(results), len(groups))
+		fmt.Println(strings.Repeat("-", 70))
+		return
+	}
+
+	sizes := clusterSizes(results)
+	for i, result := range results {
+		fmt.Printf("\n[%d] URL: %s\n", i+1, result.URL)
+		fmt.Printf("    Origin: %s\n", result.Origin)
+		if method := methodOf(result); method != "GET" {
+			fmt.Printf("    Method: %s\n", method)
+		}
+		if result.Status != 0 {
+			fmt.Printf("    Status: %d\n", result.Status)
+		}
+		if result.Vantage != "" {
+			fmt.Printf("    Vantage: %s\n", result.Vantage)
+		}
+		if result.Protocol == "HTTP/2.0" {
+			fmt.Printf("    Protocol: %s\n", result.Protocol)
+		}
+		if result.Encoding != "" {
+			fmt.Printf("    Content-Encoding: %s\n", result.Encoding)
+		}
+		for _, hop := range result.Redirects {
+			fmt.Printf("    Redirect: %d %s -> %s\n", hop.Status, hop.URL, hop.Location)
+		}
+		fmt.Printf("    Finding: %s\n", fingerprint(result))
+		if result.Class != "" {
+			fmt.Printf("    Class: %s (%s)\n", result.Class, result.Severity.Label())
+		}
+		if result.Verification != "" {
+			fmt.Printf("    Verification: %s\n", result.Verification)
+		}
+		if result.Headless != "" {
+			fmt.Printf("    Headless PoC: %s\n", result.Headless)
+		}
+		if result.Cluster != "" {
+			fmt.Printf("    Cluster: %s\n", clusterLabel(result, sizes))
+		}
+		if len(result.Hook) > 0 {
+			fmt.Printf("    Hook: %s\n", result.Hook)
+		}
+		if config.Curl {
+			fmt.Printf("    Reproduce: %s\n", curlCommand(result))
+		}
+		if p := result.CharProbe; p != nil {
+			fmt.Printf("    Characters reflected: %s\n", formatChars(p.Reflected))
+			fmt.Printf("    Characters altered:   %s\n", formatChars(p.Altered))
+			fmt.Printf("    Characters rejected:  %s\n", formatChars(p.Rejected))
+		}
+		
+		if result.Headers.ACAO != "" {
+			fmt.Printf("    ✓ Access-Control-Allow-Origin: %s\n", result.Headers.ACAO)
+		}
+		if result.Headers.ACAC != "" {
+			fmt.Printf("    ✓ Access-Control-Allow-Credentials: %s\n", result.Headers.ACAC)
+		}
+		if result.Headers.ACAM != "" {
+			fmt.Printf("    ✓ Access-Control-Allow-Methods: %s\n", result.Headers.ACAM)
+		}
+		if result.Headers.ACAH != "" {
+			fmt.Printf("    ✓ Access-Control-Allow-Headers: %s\n", result.Headers.ACAH)
+		}
+		if result.Headers.ACMA != "" {
+			fmt.Printf("    ✓ Access-Control-Max-Age: %s\n", result.Headers.ACMA)
+		}
+		if result.Headers.ACEH != "" {
+			fmt.Printf("    ✓ Access-Control-Expose-Headers: %s\n", result.Headers.ACEH)
+		}
+		if result.Headers.ACAPN != "" {
+			fmt.Printf("    ✓ Access-Control-Allow-Private-Network: %s\n", result.Headers.ACAPN)
+		}
+		
+		// Add potential security implications
+		for _, risk := range assessRisks(result) {
+			icon := "⚠️ "
+			if risk.Severity >= SeverityHigh {
+				icon = "🚨"
+			}
+			fmt.Printf("    %s %s: %s\n", icon, strings.ToUpper(risk.Severity.String()), risk.Message)
+		}
+	}
+	
+	fmt.Println("\n" + strings.Repeat("-", 70))
+	fmt.Printf("Summary: %d total CORS configurations found\n", len(results))
+	fmt.Println(strings.Repeat("-", 70))
+}
+
+func writeCSV() {
+	if len(results) == 0 {
+		fmt.Println("\n[*] No CORS headers found in any responses.")
+		return
+	}
+	
+	csvName := config.CSVName
+	if csvName == "" {
+		csvName = "CORS_Results-" + time.Now().Format("02Jan2006150405") + ".csv"
+	}
+	
+	// Appends keep the column layout of the existing file, so files
+	// started by older versions stay consistent.
+	header := csvHeader
+	fileExists := false
+	if existing, err := os.Open(csvName); err == nil {
+		fileExists = true
+		if first, err := csv.NewReader(existing).Read(); err == nil {
+			header = first
+		}
+		existing.Close()
+		fmt.Printf("\n[+] Appending to %s.\n", csvName)
+	} else {
+		fmt.Printf("\n[+] Writing to %s.\n", csvName)
+	}
+	
+	file, err := os.OpenFile(csvName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		log.Printf("Error opening CSV file: %v", err)
+		emitError(errOutput, csvName, err)
+		return
+	}
+	defer file.Close()
+	
+	writer := csv.NewWriter(file)
+	
+	// Write header if new file
+	if !fileExists {
+		writer.Write(header)
+	}
+	
+	// Write results
+	for _, result := range results {
+		record := make([]string, len(header))
+		for i, column := range header {
+			record[i] = csvField(result, column)
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+	signOutput(csvName)
+	
+	fmt.Printf("[*] Complete! Found %d CORS configurations.\n", len(results))
+}
\ No newline at end of file
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+// mergeResults concatenates result sets, keeping the first occurrence of
+// each finding fingerprint.
+func mergeResults(sets ...[]ScanResult) []ScanResult {
+	var merged []ScanResult
+	seen := make(map[string]bool)
+
+	for _, set := range sets {
+		for _, result := range set {
+			fp := fingerprint(result)
+			if seen[fp] {
+				continue
+			}
+			seen[fp] = true
+			merged = append(merged, result)
+		}
+	}
+	return merged
+}
+
+func newMergeCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "merge <results-file>...",
+		Short: "Merge and deduplicate result files from sharded or repeated scans",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if output == "" {
+				log.Fatal("please specify an output file (-o)")
+			}
+
+			var sets [][]ScanResult
+			total := 0
+			for _, path := range args {
+				loaded, err := loadResults(path)
+				if err != nil {
+					log.Fatal(err)
+				}
+				sets = append(sets, loaded)
+				total += len(loaded)
+			}
+
+			merged := mergeResults(sets...)
+			if err := saveResults(output, merged); err != nil {
+				log.Fatalf("Error writing %s: %v", output, err)
+			}
+			fmt.Printf("[+] Merged %d results from %d files into %d unique findings in %s.\n", total, len(args), len(merged), output)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "specify the merged JSON results file to write")
+
+	return cmd
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"cors-scanner/pkg/corscan"
+)
+
+// testMethodFuzz is the test name of --fuzz-methods results.
+const testMethodFuzz = "method-fuzz"
+
+// fuzzedMethods are the state-changing or dangerous methods --fuzz-methods
+// asks preflights about.
+var fuzzedMethods = []string{"PUT", "DELETE", "PATCH", "TRACE"}
+
+// MethodFuzz is what a URL's preflights allowed a foreign origin.
+type MethodFuzz struct {
+	Origin      string
+	Allowed     []string
+	Credentials bool
+}
+
+// fuzzPreflightMethods sends a preflight per fuzzed method from a random
+// foreign origin, from every vantage point. The responses become results
+// of the method-fuzz test, with the method asked about as FuzzMethod.
+func fuzzPreflightMethods(ctx context.Context, engine *corscan.Scanner, targetURL string) {
+	probes, err := corscan.Probes(targetURL, "reflected")
+	if err != nil {
+		return
+	}
+	origin := probes[0].Origin
+
+	for _, v := range engine.Vantages() {
+		for _, method := range fuzzedMethods {
+			if ctx.Err() != nil {
+				return
+			}
+			engine.Do(ctx, v, testMethodFuzz, corscan.Request{
+				Method: http.MethodOptions,
+				URL:    targetURL,
+				Origin: origin,
+				Header: http.Header{"Access-Control-Request-Method": {method}},
+			})
+		}
+	}
+}
+
+// fuzzAllowed reports whether a method-fuzz result allowed its foreign
+// origin the method its preflight asked about.
+func fuzzAllowed(result ScanResult) bool {
+	headers := result.Headers
+	if result.FuzzMethod == "" || (headers.ACAO != result.Origin && headers.ACAO != "*") {
+		return false
+	}
+	return corscan.ContainsToken(headers.ACAM, result.FuzzMethod) || corscan.ContainsToken(headers.ACAM, "*")
+}
+
+// fuzzRisks reports a --fuzz-methods preflight that allowed its foreign
+// origin the method it asked about without credentials; the library
+// already reports the credentialed case as dangerous-methods.
+func fuzzRisks(result ScanResult) []Risk {
+	if result.Test != testMethodFuzz || !fuzzAllowed(result) || result.Headers.ACAC == "true" {
+		return nil
+	}
+	return []Risk{{ID: "dangerous-methods", Severity: SeverityLow, Message: fmt.Sprintf("State-changing method allowed cross-origin without credentials: %s", result.FuzzMethod)}}
+}
+
+// printMethodFuzzes lists the URLs whose preflights allowed a foreign
+// origin one of the fuzzed methods.
+func printMethodFuzzes() {
+	if !config.FuzzMethods {
+		return
+	}
+
+	fuzzes := make(map[string]*MethodFuzz)
+	for _, result := range results {
+		if result.Test != testMethodFuzz || !fuzzAllowed(result) {
+			continue
+		}
+		key := result.URL
+		if result.Vantage != "" {
+			key += " via " + result.Vantage
+		}
+		fuzz := fuzzes[key]
+		if fuzz == nil {
+			fuzz = &MethodFuzz{Origin: result.Origin}
+			fuzzes[key] = fuzz
+		}
+		if !containsString(fuzz.Allowed, result.FuzzMethod) {
+			fuzz.Allowed = append(fuzz.Allowed, result.FuzzMethod)
+		}
+		fuzz.Credentials = fuzz.Credentials || result.Headers.ACAC == "true"
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 70))
+	fmt.Printf("PREFLIGHT METHOD FUZZING (%s)\n", strings.Join(fuzzedMethods, ", "))
+	fmt.Println(strings.Repeat("=", 70))
+	if len(fuzzes) == 0 {
+		fmt.Println("\n[*] No URL allowed a foreign origin any of the fuzzed methods.")
+		return
+	}
+
+	keys := make([]string, 0, len(fuzzes))
+	for key := range fuzzes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fuzz := fuzzes[key]
+		fmt.Printf("\n%s\n    Origin:  %s\n    Allowed: %s\n", key, fuzz.Origin, strings.Join(fuzz.Allowed, ", "))
+		if fuzz.Credentials {
+			fmt.Println("    [!] With credentials: a foreign page can send these authenticated requests.")
+		}
+	}
+}
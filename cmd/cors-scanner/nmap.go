@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// nmapRun is the part of Nmap's XML output (-oX) needed to find web
+// services.
+type nmapRun struct {
+	Hosts []struct {
+		Status struct {
+			State string `xml:"state,attr"`
+		} `xml:"status"`
+		Addresses []struct {
+			Addr     string `xml:"addr,attr"`
+			AddrType string `xml:"addrtype,attr"`
+		} `xml:"address"`
+		Hostnames []struct {
+			Name string `xml:"name,attr"`
+			Type string `xml:"type,attr"`
+		} `xml:"hostnames>hostname"`
+		Ports []struct {
+			Protocol string `xml:"protocol,attr"`
+			PortID   string `xml:"portid,attr"`
+			State    struct {
+				State string `xml:"state,attr"`
+			} `xml:"state"`
+			Service struct {
+				Name   string `xml:"name,attr"`
+				Tunnel string `xml:"tunnel,attr"`
+			} `xml:"service"`
+		} `xml:"ports>port"`
+	} `xml:"host"`
+}
+
+func isNmapXML(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".xml")
+}
+
+// loadNmapXML builds target URLs from the open HTTP and HTTPS services in
+// an Nmap XML file. Hosts are addressed by the name given on the Nmap
+// command line when there was one, else by their IP address.
+func loadNmapXML(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open file: %v", err)
+	}
+	var run nmapRun
+	if err := xml.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+
+	var urls []string
+	seen := make(map[string]bool)
+	for _, host := range run.Hosts {
+		if host.Status.State != "" && host.Status.State != "up" {
+			continue
+		}
+
+		name := ""
+		for _, hostname := range host.Hostnames {
+			if hostname.Type == "user" {
+				name = hostname.Name
+			}
+		}
+		for _, addr := range host.Addresses {
+			if name == "" && (addr.AddrType == "ipv4" || addr.AddrType == "ipv6") {
+				name = addr.Addr
+			}
+		}
+		if name == "" {
+			continue
+		}
+
+		for _, port := range host.Ports {
+			if port.Protocol != "tcp" || port.State.State != "open" {
+				continue
+			}
+			service := strings.ToLower(port.Service.Name)
+			if !strings.Contains(service, "http") {
+				continue
+			}
+			scheme := "http"
+			if port.Service.Tunnel == "ssl" || strings.HasPrefix(service, "https") || strings.HasPrefix(service, "ssl/") {
+				scheme = "https"
+			}
+
+			hostPort := net.JoinHostPort(name, port.PortID)
+			if (scheme == "http" && port.PortID == "80") || (scheme == "https" && port.PortID == "443") {
+				hostPort = name
+				if strings.Contains(name, ":") {
+					hostPort = "[" + name + "]"
+				}
+			}
+			target := scheme + "://" + hostPort + "/"
+			if !seen[target] {
+				seen[target] = true
+				urls = append(urls, target)
+			}
+		}
+	}
+	return urls, nil
+}
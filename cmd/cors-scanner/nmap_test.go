@@ -0,0 +1,59 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+const nmapXML = `<?xml version="1.0"?>
+<nmaprun scanner="nmap">
+  <host>
+    <status state="up"/>
+    <address addr="203.0.113.10" addrtype="ipv4"/>
+    <hostnames><hostname name="www.example.com" type="user"/><hostname name="ptr.example.net" type="PTR"/></hostnames>
+    <ports>
+      <port protocol="tcp" portid="80"><state state="open"/><service name="http"/></port>
+      <port protocol="tcp" portid="443"><state state="open"/><service name="http" tunnel="ssl"/></port>
+      <port protocol="tcp" portid="8443"><state state="open"/><service name="https-alt"/></port>
+      <port protocol="tcp" portid="22"><state state="open"/><service name="ssh"/></port>
+      <port protocol="tcp" portid="8080"><state state="closed"/><service name="http-proxy"/></port>
+    </ports>
+  </host>
+  <host>
+    <status state="up"/>
+    <address addr="2001:db8::1" addrtype="ipv6"/>
+    <address addr="00:11:22:33:44:55" addrtype="mac"/>
+    <ports>
+      <port protocol="tcp" portid="80"><state state="open"/><service name="http"/></port>
+      <port protocol="tcp" portid="8000"><state state="open"/><service name="http-alt"/></port>
+    </ports>
+  </host>
+  <host>
+    <status state="down"/>
+    <address addr="203.0.113.11" addrtype="ipv4"/>
+    <ports>
+      <port protocol="tcp" portid="80"><state state="open"/><service name="http"/></port>
+    </ports>
+  </host>
+</nmaprun>`
+
+func TestLoadNmapXML(t *testing.T) {
+	got, err := loadNmapXML(writeTemp(t, "scan.xml", nmapXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"http://www.example.com/",
+		"https://www.example.com/",
+		"https://www.example.com:8443/",
+		"http://[2001:db8::1]/",
+		"http://[2001:db8::1]:8000/",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loadNmapXML = %v, want %v", got, want)
+	}
+
+	if _, err := loadNmapXML(writeTemp(t, "broken.xml", "<nmaprun><host>")); err == nil {
+		t.Error("loadNmapXML accepted truncated XML")
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// defaultPorts are the ports normalizeURL drops from URLs of each scheme.
+var defaultPorts = map[string]string{"http": "80", "https": "443"}
+
+// normalizeURL rewrites a URL to the form duplicates share: lower-case
+// scheme and host, no default port, no fragment, and no trailing slash
+// except for the root path. URLs that do not parse are returned as given.
+func normalizeURL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return raw
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	host := strings.ToLower(parsed.Hostname())
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]" // IPv6
+	}
+	if port := parsed.Port(); port != "" && port != defaultPorts[parsed.Scheme] {
+		host += ":" + port
+	}
+	parsed.Host = host
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+
+	if parsed.Path == "" {
+		parsed.Path = "/"
+	} else if len(parsed.Path) > 1 {
+		parsed.Path = strings.TrimRight(parsed.Path, "/")
+		parsed.RawPath = strings.TrimRight(parsed.RawPath, "/")
+		if parsed.Path == "" {
+			parsed.Path = "/"
+		}
+	}
+	return parsed.String()
+}
+
+// dedupeURLs normalises input URLs and drops the duplicates, keeping the
+// first of each, since every URL costs a request per test. --no-dedupe
+// scans the URLs exactly as given.
+func dedupeURLs(urls []string) []string {
+	if config.NoDedupe {
+		return urls
+	}
+
+	var kept []string
+	seen := make(map[string]bool)
+	for _, raw := range urls {
+		target := normalizeURL(raw)
+		inheritTargetOpts(raw, target)
+		if seen[target] {
+			continue
+		}
+		seen[target] = true
+		kept = append(kept, target)
+	}
+
+	if dropped := len(urls) - len(kept); dropped > 0 {
+		fmt.Printf("[*] Normalised input: %d of %d URLs are duplicates, scanning %d.\n", dropped, len(urls), len(kept))
+	}
+	return kept
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"root path added", "https://example.com", "https://example.com/"},
+		{"scheme and host lower-cased", "HTTPS://Example.COM/Path", "https://example.com/Path"},
+		{"default https port dropped", "https://example.com:443/api", "https://example.com/api"},
+		{"default http port dropped", "http://example.com:80/", "http://example.com/"},
+		{"other port kept", "https://example.com:8443/", "https://example.com:8443/"},
+		{"port of the other scheme kept", "http://example.com:443/", "http://example.com:443/"},
+		{"fragment dropped", "https://example.com/page#top", "https://example.com/page"},
+		{"trailing slashes dropped", "https://example.com/api//", "https://example.com/api"},
+		{"query kept", "https://example.com/search?q=1", "https://example.com/search?q=1"},
+		{"IPv6 host", "http://[::1]:80/", "http://[::1]/"},
+		{"IPv6 host with port", "http://[FE80::1]:8080/x/", "http://[fe80::1]:8080/x"},
+		{"no host returned as given", "example.com/path", "example.com/path"},
+		{"unparsable returned as given", "http://[::1/", "http://[::1/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeURL(tt.raw); got != tt.want {
+				t.Errorf("normalizeURL(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDedupeURLs(t *testing.T) {
+	tests := []struct {
+		name     string
+		noDedupe bool
+		urls     []string
+		want     []string
+	}{
+		{
+			name: "duplicates dropped keeping the first",
+			urls: []string{"https://example.com", "https://EXAMPLE.com:443/", "https://example.com/#x", "https://example.com/api/"},
+			want: []string{"https://example.com/", "https://example.com/api"},
+		},
+		{
+			name: "distinct URLs kept in order",
+			urls: []string{"https://b.example.com/", "https://a.example.com/"},
+			want: []string{"https://b.example.com/", "https://a.example.com/"},
+		},
+		{
+			name:     "--no-dedupe keeps the input",
+			noDedupe: true,
+			urls:     []string{"https://example.com", "https://example.com/"},
+			want:     []string{"https://example.com", "https://example.com/"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.NoDedupe = tt.noDedupe
+			defer func() { config.NoDedupe = false }()
+
+			if got := dedupeURLs(tt.urls); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dedupeURLs(%q) = %q, want %q", tt.urls, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDedupeURLsKeepsTargetOptions(t *testing.T) {
+	opts := &targetOptions{Methods: []string{"POST"}}
+	targetOpts["https://Example.com:443"] = opts
+	defer func() {
+		delete(targetOpts, "https://Example.com:443")
+		delete(targetOpts, "https://example.com/")
+	}()
+
+	dedupeURLs([]string{"https://Example.com:443"})
+	if targetOpts["https://example.com/"] != opts {
+		t.Errorf("options of https://Example.com:443 not found under the normalised URL")
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"cors-scanner/pkg/corscan"
+)
+
+// originList holds the --origin-list origins, sent to every URL after the
+// built-in tests.
+var originList []string
+
+// loadOriginList reads one origin per line, skipping blank lines and #
+// comments.
+func loadOriginList(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("cannot open origin list: %v", err)
+	}
+	defer file.Close()
+
+	originList = nil
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		origin := strings.TrimSpace(scanner.Text())
+		if origin == "" || strings.HasPrefix(origin, "#") {
+			continue
+		}
+		originList = append(originList, origin)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading %s: %v", path, err)
+	}
+	if len(originList) == 0 {
+		return fmt.Errorf("no origins in %s", path)
+	}
+	return nil
+}
+
+// printAllowedListOrigins lists, per URL, the --origin-list origins the
+// target allowed by name.
+func printAllowedListOrigins() {
+	if len(originList) == 0 {
+		return
+	}
+
+	allowed := make(map[string][]string)
+	for _, result := range results {
+		if result.Test != corscan.OriginListTest || result.Headers.ACAO != result.Origin {
+			continue
+		}
+		if !containsString(allowed[result.URL], result.Origin) {
+			allowed[result.URL] = append(allowed[result.URL], result.Origin)
+		}
+	}
+	if len(allowed) == 0 {
+		return
+	}
+	urls := make([]string, 0, len(allowed))
+	for u := range allowed {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+
+	fmt.Println("\n" + strings.Repeat("=", 70))
+	fmt.Printf("ORIGIN LIST - %d URLs allow origins from %s\n", len(urls), config.OriginList)
+	fmt.Println(strings.Repeat("=", 70))
+	for _, u := range urls {
+		fmt.Printf("\n%s\n    Allowed: %s\n", u, strings.Join(allowed[u], ", "))
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"cors-scanner/pkg/corscan"
+	"github.com/spf13/cobra"
+)
+
+// paceProfile bundles the settings that control scan intensity.
+type paceProfile struct {
+	Threads int
+	Timeout int
+	Delay   time.Duration
+	Jitter  time.Duration
+	Retries int
+}
+
+var paceProfiles = map[string]paceProfile{
+	"aggressive": {Threads: 50, Timeout: 5, Delay: 0, Jitter: 0, Retries: 0},
+	"normal":     {Threads: 10, Timeout: 10, Delay: 0, Jitter: 0, Retries: 1},
+	"paranoid":   {Threads: 1, Timeout: 20, Delay: 3 * time.Second, Jitter: 2 * time.Second, Retries: 3},
+}
+
+// applyPace copies the selected profile into config. Flags given explicitly
+// on the command line win over the profile.
+func applyPace(cmd *cobra.Command) error {
+	if config.Pace == "" {
+		return nil
+	}
+	profile, ok := paceProfiles[strings.ToLower(config.Pace)]
+	if !ok {
+		return fmt.Errorf("unknown pace %q (use aggressive, normal or paranoid)", config.Pace)
+	}
+
+	if !cmd.Flags().Changed("threads") {
+		config.Threads = profile.Threads
+	}
+	if !cmd.Flags().Changed("timeout") {
+		config.Timeout = profile.Timeout
+	}
+	if !cmd.Flags().Changed("delay") {
+		config.Delay = profile.Delay
+	}
+	if !cmd.Flags().Changed("jitter") {
+		config.Jitter = profile.Jitter
+	}
+	if !cmd.Flags().Changed("retries") {
+		config.Retries = profile.Retries
+	}
+	return nil
+}
+
+var (
+	paceMux     sync.Mutex
+	lastRequest time.Time
+)
+
+// pace spaces requests the configured delay plus a random share of the
+// jitter apart. The spacing is global, across all threads, so a burst of
+// test requests never reaches a target faster than the delay allows.
+func pace(ctx context.Context) error {
+	wait := config.Delay
+	if config.Jitter > 0 {
+		wait += time.Duration(rand.Int63n(int64(config.Jitter)))
+	}
+	if wait <= 0 {
+		return nil
+	}
+
+	paceMux.Lock()
+	slot := lastRequest.Add(wait)
+	if now := time.Now(); slot.Before(now) {
+		slot = now
+	}
+	lastRequest = slot
+	paceMux.Unlock()
+
+	return sleepContext(ctx, time.Until(slot))
+}
+
+// sleepContext pauses for d, or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sendWithRetry paces and sends a request, retrying transport errors with
+// exponential backoff. Rate-limited responses back the host off and are
+// re-sent separately from the error retries.
+func sendWithRetry(ctx context.Context, client *http.Client, r corscan.Request) (*http.Response, error) {
+	targetURL := r.URL
+	backoff := time.Second
+	throttled := 0
+	for attempt := 0; ; attempt++ {
+		if err := waitToSend(ctx, targetURL); err != nil {
+			return nil, err
+		}
+		resp, err := makeRequest(ctx, client, r)
+		if err == nil && isThrottled(resp) && throttled < maxThrottleRetries {
+			throttled++
+			attempt--
+			wait := recordThrottle(targetURL, resp)
+			resp.Body.Close()
+			metrics.Incr("throttled")
+			if config.Verbose {
+				fmt.Printf("Throttled by %s (HTTP %d), backing off %s\n", throttleHost(targetURL), resp.StatusCode, wait)
+			}
+			continue
+		}
+		if err == nil || attempt >= config.Retries || ctx.Err() != nil {
+			return resp, err
+		}
+		if config.Verbose {
+			fmt.Printf("Retrying %s in %s after error: %v\n", targetURL, backoff, err)
+		}
+		if err := sleepContext(ctx, backoff); err != nil {
+			return nil, err
+		}
+		backoff *= 2
+	}
+}
+
+// waitToSend blocks until a request to targetURL may go out: inside the
+// scan window, after any host backoff and paced behind earlier requests.
+func waitToSend(ctx context.Context, targetURL string) error {
+	if err := waitForScanWindow(ctx); err != nil {
+		return err
+	}
+	if err := waitForHost(ctx, targetURL); err != nil {
+		return err
+	}
+	return pace(ctx)
+}
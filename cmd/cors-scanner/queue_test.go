@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestJobQueue(t *testing.T) {
+	q, err := openQueue(filepath.Join(t.TempDir(), "scan.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added, err := q.Add([]string{"https://a.example.com/", "https://b.example.com/"}); err != nil || added != 2 {
+		t.Fatalf("Add = %d, %v, want 2 new targets", added, err)
+	}
+	if added, err := q.Add([]string{"https://a.example.com/", "https://c.example.com/"}); err != nil || added != 1 {
+		t.Fatalf("Add = %d, %v, want 1 new target", added, err)
+	}
+
+	url, ok, err := q.Claim()
+	if err != nil || !ok || url != "https://a.example.com/" {
+		t.Fatalf("Claim = %q, %v, %v, want the oldest target", url, ok, err)
+	}
+	found := []ScanResult{{}}
+	found[0].URL = url
+	if err := q.Complete(url, found); err != nil {
+		t.Fatal(err)
+	}
+
+	url, _, _ = q.Claim()
+	if err := q.Release(url); err != nil {
+		t.Fatal(err)
+	}
+
+	// The released target goes to the back of the line.
+	var order []string
+	for {
+		url, ok, err := q.Claim()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			break
+		}
+		order = append(order, url)
+	}
+	if len(order) != 2 || order[0] != "https://c.example.com/" || order[1] != "https://b.example.com/" {
+		t.Errorf("claimed %v after release, want c then b", order)
+	}
+
+	counts, err := q.Counts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["done"] != 1 || counts["running"] != 2 || counts["pending"] != 0 {
+		t.Errorf("Counts = %v, want 1 done and 2 running", counts)
+	}
+	results, err := q.Results()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].URL != "https://a.example.com/" {
+		t.Errorf("Results = %v, want the completed target's finding", results)
+	}
+}
+
+func TestScanQueueReleasesFailedTargets(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer up.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	down.Close()
+
+	config.Threads, selectedTests = 1, []string{"null"}
+	defer func() {
+		config.Threads, selectedTests = 0, nil
+		failures, failedURLs = make(map[string]*failedTarget), nil
+	}()
+
+	q, err := openQueue(filepath.Join(t.TempDir(), "scan.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Add([]string{up.URL + "/", down.URL + "/"}); err != nil {
+		t.Fatal(err)
+	}
+	scanQueue(context.Background(), q)
+
+	counts, err := q.Counts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["done"] != 1 || counts["pending"] != 1 {
+		t.Errorf("Counts = %v, want the unreachable target pending again", counts)
+	}
+	url, _, _ := q.Claim()
+	if url != down.URL+"/" {
+		t.Errorf("pending target is %q, want %q", url, down.URL+"/")
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// rawRequest renders the request behind a result as it goes on the wire,
+// which Burp Repeater accepts pasted as is.
+func rawRequest(result ScanResult) (string, error) {
+	req, err := newScanRequest(methodOf(result), result.URL, result.Origin)
+	if err != nil {
+		return "", err
+	}
+	dump, err := httputil.DumpRequestOut(req, false)
+	if err != nil {
+		return "", err
+	}
+	return string(dump), nil
+}
+
+// httpFileRequest turns a raw request into the .http format of the VS Code
+// REST Client and JetBrains HTTP Client, whose request line carries the
+// full URL.
+func httpFileRequest(result ScanResult, raw string) string {
+	lines := strings.Split(strings.TrimRight(raw, "\r\n"), "\r\n")
+	lines[0] = methodOf(result) + " " + result.URL + " HTTP/1.1"
+	return "# " + fingerprint(result) + " " + testName(result) + "\n" + strings.Join(lines, "\n") + "\n"
+}
+
+// writeRequestExports writes each finding's request to --export-requests
+// as <fingerprint>.txt (raw, for Burp Repeater) and <fingerprint>.http.
+func writeRequestExports() {
+	if config.RequestDir == "" {
+		return
+	}
+	if err := os.MkdirAll(config.RequestDir, 0755); err != nil {
+		log.Printf("Error creating %s: %v", config.RequestDir, err)
+		emitError(errOutput, config.RequestDir, err)
+		return
+	}
+
+	written := 0
+	for _, result := range results {
+		if len(assessRisks(result)) == 0 {
+			continue
+		}
+		raw, err := rawRequest(result)
+		if err != nil {
+			log.Printf("Error exporting request for %s: %v", result.URL, err)
+			emitError(requestErrorCode(err), result.URL, err)
+			continue
+		}
+
+		base := filepath.Join(config.RequestDir, fingerprint(result))
+		for path, content := range map[string]string{
+			base + ".txt":  raw,
+			base + ".http": httpFileRequest(result, raw),
+		} {
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				log.Printf("Error writing %s: %v", path, err)
+				emitError(errOutput, path, err)
+			}
+		}
+		written++
+	}
+	fmt.Printf("[+] Exported the requests of %d findings to %s.\n", written, config.RequestDir)
+}
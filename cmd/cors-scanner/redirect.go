@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// RedirectHop is one redirect a test request went through: the URL that
+// answered, its status, where it pointed and the ACAO it carried.
+type RedirectHop struct {
+	URL      string `json:"url"`
+	Status   int    `json:"status"`
+	Location string `json:"location"`
+	ACAO     string `json:"acao,omitempty"`
+}
+
+// checkRedirect applies --follow-redirects and --max-redirects, and stops
+// the client from following a redirect out of scope. Without
+// --follow-redirects the redirect itself is the result.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if !config.FollowRedirects {
+		return http.ErrUseLastResponse
+	}
+	if len(via) > config.MaxRedirects {
+		return fmt.Errorf("stopped after %d redirects", config.MaxRedirects)
+	}
+	return checkScope(req.URL.String())
+}
+
+// redirectChain returns the redirects behind a response, oldest first,
+// including the response itself when it is an unfollowed redirect.
+func redirectChain(resp *http.Response) []RedirectHop {
+	var hops []RedirectHop
+	for r := resp; r != nil && r.Request != nil; r = r.Request.Response {
+		location := r.Header.Get("Location")
+		if r.StatusCode/100 != 3 || location == "" {
+			continue
+		}
+		if next, err := r.Request.URL.Parse(location); err == nil {
+			location = next.String()
+		}
+		hops = append([]RedirectHop{{
+			URL:      r.Request.URL.String(),
+			Status:   r.StatusCode,
+			Location: location,
+			ACAO:     r.Header.Get("Access-Control-Allow-Origin"),
+		}}, hops...)
+	}
+	return hops
+}
+
+// crossOrigin reports whether a redirect leaves the origin it came from.
+func (h RedirectHop) crossOrigin() bool {
+	from, err1 := url.Parse(h.URL)
+	to, err2 := url.Parse(h.Location)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	return !strings.EqualFold(from.Scheme, to.Scheme) || !strings.EqualFold(from.Host, to.Host)
+}
+
+// redirectRisks flags what browsers do differently from a plain client
+// on cross-origin redirects. They check the CORS headers of the redirect
+// itself before following it, and send Origin: null to the next origin,
+// so a reflection found there with the test origin may be a false
+// positive.
+func redirectRisks(result ScanResult) []Risk {
+	var risks []Risk
+	crossed := false
+	for _, hop := range result.Redirects {
+		if !hop.crossOrigin() {
+			continue
+		}
+		if hop.ACAO != "" {
+			risks = append(risks, Risk{ID: "redirect-cors-headers", Severity: SeverityInfo,
+				Message: fmt.Sprintf("CORS headers on a cross-origin %d redirect to %s (ACAO: %s) - browsers check them before following", hop.Status, hop.Location, hop.ACAO)})
+		}
+		crossed = true
+	}
+	if crossed && config.FollowRedirects && result.Headers.ACAO != "" && result.Headers.ACAO == result.Origin {
+		last := result.Redirects[len(result.Redirects)-1]
+		risks = append(risks, Risk{ID: "redirect-origin-null", Severity: SeverityInfo,
+			Message: fmt.Sprintf("Reflection found after a cross-origin redirect to %s - browsers send Origin: null there, so it may be a false positive", last.Location)})
+	}
+	return risks
+}
+
+// formatRedirects writes a redirect chain on one line for CSV files.
+func formatRedirects(hops []RedirectHop) string {
+	parts := make([]string, len(hops))
+	for i, hop := range hops {
+		parts[i] = fmt.Sprintf("%d %s -> %s", hop.Status, hop.URL, hop.Location)
+		if hop.ACAO != "" {
+			parts[i] += " [ACAO: " + hop.ACAO + "]"
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+// parseRedirects reads a chain written by formatRedirects.
+func parseRedirects(s string) []RedirectHop {
+	var hops []RedirectHop
+	for _, part := range strings.Split(s, " | ") {
+		var hop RedirectHop
+		if rest, acao, ok := strings.Cut(part, " [ACAO: "); ok {
+			part, hop.ACAO = rest, strings.TrimSuffix(acao, "]")
+		}
+		status, rest, _ := strings.Cut(part, " ")
+		hop.URL, hop.Location, _ = strings.Cut(rest, " -> ")
+		var err error
+		if hop.Status, err = strconv.Atoi(status); err != nil || hop.URL == "" {
+			continue
+		}
+		hops = append(hops, hop)
+	}
+	return hops
+}
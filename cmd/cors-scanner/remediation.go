@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"cors-scanner/pkg/corscan"
+)
+
+// Remediation is shared with the scanning library.
+type Remediation = corscan.Remediation
+
+// resultRemediation returns the remediation stored with a result, or
+// derives it for results saved without one.
+func resultRemediation(result ScanResult) *Remediation {
+	if result.Remediation != nil {
+		return result.Remediation
+	}
+	return remediationFor(assessRisks(result), result.Tech)
+}
+
+// writeHTMLRemediation renders a remediation below a result's risks.
+func writeHTMLRemediation(b *strings.Builder, remediation *Remediation) {
+	if remediation == nil {
+		return
+	}
+	b.WriteString("<h3>Remediation</h3>\n<ul>\n")
+	for _, advice := range remediation.Advice {
+		fmt.Fprintf(b, "<li>%s</li>\n", html.EscapeString(advice))
+	}
+	b.WriteString("</ul>\n")
+	if remediation.Example != "" {
+		fmt.Fprintf(b, "<p>Example for %s:</p>\n<pre>%s</pre>\n", html.EscapeString(remediation.Stack), html.EscapeString(remediation.Example))
+	}
+}
+
+func writeMarkdownRemediation(b *strings.Builder, remediation *Remediation) {
+	if remediation == nil {
+		return
+	}
+	b.WriteString("\n### Remediation\n\n")
+	for _, advice := range remediation.Advice {
+		fmt.Fprintf(b, "- %s\n", advice)
+	}
+	if remediation.Example != "" {
+		fmt.Fprintf(b, "\nExample for %s:\n\n```\n%s\n```\n", remediation.Stack, remediation.Example)
+	}
+}
+
+// remediationFor returns the fixes for a set of risks, with an example for
+// the stack tech identified.
+func remediationFor(risks []Risk, tech *TechInfo) *Remediation {
+	if tech == nil {
+		return corscan.RemediationFor(risks)
+	}
+	return corscan.RemediationFor(risks, append([]string{tech.PoweredBy, tech.Server}, tech.Edge...)...)
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// corsHeaderRows lists the captured CORS headers of a result by their full
+// names, skipping the ones the server did not send.
+func corsHeaderRows(headers CORSHeaders) [][2]string {
+	var rows [][2]string
+	for _, h := range [][2]string{
+		{"Access-Control-Allow-Origin", headers.ACAO},
+		{"Access-Control-Allow-Credentials", headers.ACAC},
+		{"Access-Control-Allow-Methods", headers.ACAM},
+		{"Access-Control-Allow-Headers", headers.ACAH},
+		{"Access-Control-Max-Age", headers.ACMA},
+		{"Access-Control-Expose-Headers", headers.ACEH},
+		{"Access-Control-Allow-Private-Network", headers.ACAPN},
+		{"All Access-Control-Allow-Origin values", strings.Join(headers.ACAOValues, " | ")},
+	} {
+		if h[1] != "" {
+			rows = append(rows, h)
+		}
+	}
+	return rows
+}
+
+// sortedHeaderLines renders a header set as "Name: value" lines in a
+// stable order.
+func sortedHeaderLines(header http.Header) []string {
+	var names []string
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var lines []string
+	for _, name := range names {
+		for _, value := range header[name] {
+			lines = append(lines, name+": "+value)
+		}
+	}
+	return lines
+}
+
+// formatCounts renders ranked counts as "name (n), name (n)".
+func formatCounts(entries []countEntry) string {
+	parts := make([]string, len(entries))
+	for i, entry := range entries {
+		parts[i] = fmt.Sprintf("%s (%d)", entry.Name, entry.Count)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func severityCounts(list []ScanResult) map[Severity]int {
+	counts := make(map[Severity]int)
+	for _, result := range list {
+		counts[result.Severity]++
+	}
+	return counts
+}
+
+func renderHTMLReport(list []ScanResult) string {
+	var b strings.Builder
+	b.WriteString(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>CORS Scan Report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+td, th { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+.sev { font-weight: bold; text-transform: uppercase; }
+.critical { color: #fff; background: #8b0000; }
+.high { color: #fff; background: #d9534f; }
+.medium { background: #f0ad4e; }
+.low { background: #f7e08a; }
+.info { background: #e8e8e8; }
+</style>
+</head>
+<body>
+<h1>CORS Scan Report</h1>
+`)
+	fmt.Fprintf(&b, "<p>Generated %s &mdash; %d CORS configurations.</p>\n", time.Now().Format(time.RFC1123), len(list))
+	if signingKey != nil {
+		fmt.Fprintf(&b, "<p>Results digest (SHA-256 of the JSON results): <code>%s</code></p>\n", resultsDigest(list))
+	}
+
+	counts := severityCounts(list)
+	b.WriteString("<table>\n<tr><th>Severity</th><th>Count</th></tr>\n")
+	for s := SeverityCritical; s >= SeverityInfo; s-- {
+		fmt.Fprintf(&b, "<tr><td class=\"sev %s\">%s</td><td>%d</td></tr>\n", s, s, counts[s])
+	}
+	b.WriteString("</table>\n")
+
+	if stacks, risks := stackFindings(list); len(stacks) > 0 {
+		b.WriteString("<table>\n<tr><th>Stack</th><th>Findings</th><th>Most common risks</th></tr>\n")
+		for _, entry := range topCounts(stacks, 0) {
+			fmt.Fprintf(&b, "<tr><td>%s</td><td>%d</td><td>%s</td></tr>\n", html.EscapeString(entry.Name), entry.Count, html.EscapeString(formatCounts(topCounts(risks[entry.Name], 3))))
+		}
+		b.WriteString("</table>\n")
+	}
+
+	if config.Group {
+		writeHTMLGroups(&b, groupByURL(list), clusterSizes(list))
+		b.WriteString("</body>\n</html>\n")
+		return b.String()
+	}
+
+	sizes := clusterSizes(list)
+	for i, result := range list {
+		risks := assessRisks(result)
+		fmt.Fprintf(&b, "<h2>[%d] %s</h2>\n<table>\n", i+1, html.EscapeString(result.URL))
+		fmt.Fprintf(&b, "<tr><th>Severity</th><td class=\"sev %s\">%s</td></tr>\n", result.Severity, result.Severity)
+		if result.Class != "" {
+			fmt.Fprintf(&b, "<tr><th>Class</th><td>%s</td></tr>\n", result.Class)
+		}
+		if result.Verification != "" {
+			fmt.Fprintf(&b, "<tr><th>Verification</th><td>%s</td></tr>\n", result.Verification)
+		}
+		if result.Headless != "" {
+			fmt.Fprintf(&b, "<tr><th>Headless PoC</th><td>%s</td></tr>\n", result.Headless)
+		}
+		fmt.Fprintf(&b, "<tr><th>Finding</th><td><code>%s</code></td></tr>\n", fingerprint(result))
+		if result.Test != "" {
+			fmt.Fprintf(&b, "<tr><th>Test</th><td>%s</td></tr>\n", html.EscapeString(result.Test))
+		}
+		fmt.Fprintf(&b, "<tr><th>Origin</th><td><code>%s</code></td></tr>\n", html.EscapeString(result.Origin))
+		if result.Vantage != "" {
+			fmt.Fprintf(&b, "<tr><th>Vantage</th><td>%s</td></tr>\n", html.EscapeString(result.Vantage))
+		}
+		if result.Protocol != "" {
+			fmt.Fprintf(&b, "<tr><th>Protocol</th><td>%s</td></tr>\n", html.EscapeString(result.Protocol))
+		}
+		if result.Status != 0 {
+			fmt.Fprintf(&b, "<tr><th>Status</th><td>%d</td></tr>\n", result.Status)
+		}
+		if result.Certificate != nil {
+			fmt.Fprintf(&b, "<tr><th>Certificate</th><td>%s</td></tr>\n", html.EscapeString(result.Certificate.Summary()))
+		}
+		if result.Tech != nil {
+			fmt.Fprintf(&b, "<tr><th>Stack</th><td>%s</td></tr>\n", html.EscapeString(result.Tech.Summary()))
+		}
+		if result.Cluster != "" {
+			fmt.Fprintf(&b, "<tr><th>Cluster</th><td>%s</td></tr>\n", html.EscapeString(clusterLabel(result, sizes)))
+		}
+		for _, row := range corsHeaderRows(result.Headers) {
+			fmt.Fprintf(&b, "<tr><th>%s</th><td><code>%s</code></td></tr>\n", row[0], html.EscapeString(row[1]))
+		}
+		if config.Curl {
+			fmt.Fprintf(&b, "<tr><th>Reproduce</th><td><code>%s</code></td></tr>\n", html.EscapeString(curlCommand(result)))
+		}
+		b.WriteString("</table>\n")
+
+		if len(result.ResponseHeaders) > 0 {
+			b.WriteString("<details><summary>All response headers</summary>\n<pre>")
+			for _, line := range sortedHeaderLines(result.ResponseHeaders) {
+				b.WriteString(html.EscapeString(line) + "\n")
+			}
+			b.WriteString("</pre></details>\n")
+		}
+
+		if len(risks) > 0 {
+			b.WriteString("<ul>\n")
+			for _, risk := range risks {
+				fmt.Fprintf(&b, "<li><span class=\"sev %s\">%s</span> %s</li>\n", risk.Severity, risk.Severity, html.EscapeString(risk.Message))
+			}
+			b.WriteString("</ul>\n")
+		}
+		writeHTMLRemediation(&b, resultRemediation(result))
+	}
+
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+func renderMarkdownReport(list []ScanResult) string {
+	var b strings.Builder
+	b.WriteString("# CORS Scan Report\n\n")
+	fmt.Fprintf(&b, "Generated %s — %d CORS configurations.\n\n", time.Now().Format(time.RFC1123), len(list))
+	if signingKey != nil {
+		fmt.Fprintf(&b, "Results digest (SHA-256 of the JSON results): `%s`\n\n", resultsDigest(list))
+	}
+
+	counts := severityCounts(list)
+	b.WriteString("| Severity | Count |\n|----------|-------|\n")
+	for s := SeverityCritical; s >= SeverityInfo; s-- {
+		fmt.Fprintf(&b, "| %s | %d |\n", s.Label(), counts[s])
+	}
+
+	if stacks, risks := stackFindings(list); len(stacks) > 0 {
+		b.WriteString("\n| Stack | Findings | Most common risks |\n|-------|----------|-------------------|\n")
+		for _, entry := range topCounts(stacks, 0) {
+			fmt.Fprintf(&b, "| %s | %d | %s |\n", entry.Name, entry.Count, formatCounts(topCounts(risks[entry.Name], 3)))
+		}
+	}
+
+	if config.Group {
+		writeMarkdownGroups(&b, groupByURL(list), clusterSizes(list))
+		return b.String()
+	}
+
+	sizes := clusterSizes(list)
+	for i, result := range list {
+		risks := assessRisks(result)
+		fmt.Fprintf(&b, "\n## [%d] %s\n\n", i+1, result.URL)
+		fmt.Fprintf(&b, "- **Severity:** %s\n", result.Severity.Label())
+		if result.Class != "" {
+			fmt.Fprintf(&b, "- **Class:** %s\n", result.Class)
+		}
+		if result.Verification != "" {
+			fmt.Fprintf(&b, "- **Verification:** %s\n", result.Verification)
+		}
+		if result.Headless != "" {
+			fmt.Fprintf(&b, "- **Headless PoC:** %s\n", result.Headless)
+		}
+		fmt.Fprintf(&b, "- **Finding:** `%s`\n", fingerprint(result))
+		if result.Test != "" {
+			fmt.Fprintf(&b, "- **Test:** %s\n", result.Test)
+		}
+		fmt.Fprintf(&b, "- **Origin:** `%s`\n", result.Origin)
+		if result.Vantage != "" {
+			fmt.Fprintf(&b, "- **Vantage:** %s\n", result.Vantage)
+		}
+		if result.Protocol != "" {
+			fmt.Fprintf(&b, "- **Protocol:** %s\n", result.Protocol)
+		}
+		if result.Status != 0 {
+			fmt.Fprintf(&b, "- **Status:** %d\n", result.Status)
+		}
+		if result.Certificate != nil {
+			fmt.Fprintf(&b, "- **Certificate:** %s\n", result.Certificate.Summary())
+		}
+		if result.Tech != nil {
+			fmt.Fprintf(&b, "- **Stack:** %s\n", result.Tech.Summary())
+		}
+		if result.Cluster != "" {
+			fmt.Fprintf(&b, "- **Cluster:** %s\n", clusterLabel(result, sizes))
+		}
+		for _, row := range corsHeaderRows(result.Headers) {
+			fmt.Fprintf(&b, "- **%s:** `%s`\n", row[0], row[1])
+		}
+		if config.Curl {
+			fmt.Fprintf(&b, "\n```sh\n%s\n```\n", curlCommand(result))
+		}
+		if len(result.ResponseHeaders) > 0 {
+			b.WriteString("\n<details><summary>All response headers</summary>\n\n```\n")
+			for _, line := range sortedHeaderLines(result.ResponseHeaders) {
+				b.WriteString(line + "\n")
+			}
+			b.WriteString("```\n\n</details>\n")
+		}
+		for _, risk := range risks {
+			fmt.Fprintf(&b, "\n> **%s:** %s\n", strings.ToUpper(risk.Severity.String()), risk.Message)
+		}
+		writeMarkdownRemediation(&b, resultRemediation(result))
+	}
+
+	return b.String()
+}
+
+// writeReports writes the HTML and Markdown reports requested on the
+// command line.
+func writeReports() {
+	reports := []struct {
+		name   string
+		file   string
+		render func([]ScanResult) string
+	}{
+		{"HTML report", config.HTMLFile, renderHTMLReport},
+		{"Markdown report", config.MarkdownFile, renderMarkdownReport},
+	}
+
+	for _, report := range reports {
+		if report.file == "" {
+			continue
+		}
+		if err := os.WriteFile(report.file, []byte(report.render(results)), 0644); err != nil {
+			log.Printf("Error writing %s: %v", report.name, err)
+			emitError(errOutput, report.file, err)
+			continue
+		}
+		fmt.Printf("[+] Wrote %s to %s.\n", report.name, report.file)
+		signOutput(report.file)
+	}
+}
+
+func newReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report <results-file>",
+		Short: "Re-render reports from previously saved results",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			loaded, err := loadResults(args[0])
+			if err != nil {
+				log.Fatal(err)
+			}
+			results = loaded
+
+			if config.CSVName == "" && config.JSONFile == "" && config.HTMLFile == "" && config.MarkdownFile == "" &&
+				config.DojoExport == "" && config.DradisFile == "" && config.FaradayFile == "" && config.SARIFFile == "" &&
+				config.RequestDir == "" && config.PoCDir == "" {
+				log.Fatal("please specify at least one output format")
+			}
+
+			if config.CSVName != "" {
+				writeCSV()
+			}
+			writeJSON()
+			writeReports()
+			writeRequestExports()
+			writePoCFiles()
+			writeDojoExport()
+			writeReportingPlatformExports()
+		},
+	}
+
+	cmd.Flags().StringVar(&config.CSVName, "csv", "", "specify a CSV file to write")
+	cmd.Flags().StringVar(&config.JSONFile, "json", "", "specify a JSON results file to write")
+	cmd.Flags().StringVar(&config.HTMLFile, "html", "", "specify an HTML report file to write")
+	cmd.Flags().StringVar(&config.MarkdownFile, "markdown", "", "specify a Markdown report file to write")
+	cmd.Flags().StringVar(&config.RequestDir, "export-requests", "", "specify a directory to write each finding's raw request to")
+	cmd.Flags().StringVar(&config.PoCDir, "poc-dir", "", "specify a directory to write a ready-to-host HTML PoC page for each exploitable finding to")
+	cmd.Flags().StringVar(&config.DojoExport, "dojo-export", "", "specify a file to write DefectDojo Generic Findings Import JSON to")
+	cmd.Flags().StringVar(&config.DradisFile, "dradis", "", "specify a file to write a Dradis project template (XML) to")
+	cmd.Flags().StringVar(&config.FaradayFile, "faraday", "", "specify a file to write a Faraday JSON report to")
+	cmd.Flags().StringVar(&config.SARIFFile, "sarif", "", "specify a file to write a SARIF 2.1.0 log to")
+
+	return cmd
+}
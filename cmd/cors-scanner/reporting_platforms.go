@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dradis project templates: one issue per risk class, one host node per
+// target host and one piece of evidence per affected URL.
+
+type dradisTemplate struct {
+	XMLName xml.Name      `xml:"dradis-template"`
+	Version string        `xml:"version,attr"`
+	Nodes   []dradisNode  `xml:"nodes>node"`
+	Issues  []dradisIssue `xml:"issues>issue"`
+}
+
+type dradisNode struct {
+	ID       int              `xml:"id"`
+	Label    string           `xml:"label"`
+	ParentID string           `xml:"parent-id"`
+	Position int              `xml:"position"`
+	TypeID   int              `xml:"type-id"`
+	Evidence []dradisEvidence `xml:"evidence>evidence"`
+}
+
+type dradisEvidence struct {
+	ID      int    `xml:"id"`
+	Author  string `xml:"author"`
+	Content cdata  `xml:"content"`
+	IssueID int    `xml:"issue-id"`
+}
+
+type dradisIssue struct {
+	ID     int    `xml:"id"`
+	Author string `xml:"author"`
+	Text   cdata  `xml:"text"`
+}
+
+type cdata struct {
+	Text string `xml:",cdata"`
+}
+
+const dradisHostNodeType = 1
+
+func buildDradisProject() ([]byte, error) {
+	project := dradisTemplate{Version: "3"}
+	issueIDs := make(map[string]int)
+	nodeIndex := make(map[string]int)
+	evidenceID := 0
+
+	for _, result := range results {
+		host := result.URL
+		if parsedURL, err := url.Parse(result.URL); err == nil {
+			host = parsedURL.Host
+		}
+
+		for _, risk := range assessRisks(result) {
+			issueID, ok := issueIDs[risk.ID]
+			if !ok {
+				issueID = len(project.Issues) + 1
+				issueIDs[risk.ID] = issueID
+				project.Issues = append(project.Issues, dradisIssue{
+					ID:     issueID,
+					Author: "cors-scanner",
+					Text: cdata{fmt.Sprintf("#[Title]#\nCORS: %s\n\n#[Severity]#\n%s\n\n#[Description]#\n%s\n",
+						risk.ID, risk.Severity.Label(), risk.Message)},
+				})
+			}
+
+			index, ok := nodeIndex[host]
+			if !ok {
+				index = len(project.Nodes)
+				nodeIndex[host] = index
+				project.Nodes = append(project.Nodes, dradisNode{
+					ID:       index + 1,
+					Label:    host,
+					Position: index,
+					TypeID:   dradisHostNodeType,
+				})
+			}
+
+			evidenceID++
+			project.Nodes[index].Evidence = append(project.Nodes[index].Evidence, dradisEvidence{
+				ID:      evidenceID,
+				Author:  "cors-scanner",
+				Content: cdata{fmt.Sprintf("#[Location]#\n%s\n\n#[Output]#\nbc.. %s\n", result.URL, findingEvidence(result))},
+				IssueID: issueID,
+			})
+		}
+	}
+
+	data, err := xml.MarshalIndent(project, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// Faraday bulk-create documents: hosts carry services, services carry web
+// vulnerabilities.
+
+type faradayReport struct {
+	Hosts   []*faradayHost `json:"hosts"`
+	Command faradayCommand `json:"command"`
+}
+
+type faradayCommand struct {
+	Tool      string `json:"tool"`
+	Command   string `json:"command"`
+	Params    string `json:"params"`
+	StartDate string `json:"start_date"`
+}
+
+type faradayHost struct {
+	IP          string            `json:"ip"`
+	Description string            `json:"description"`
+	Hostnames   []string          `json:"hostnames"`
+	Services    []*faradayService `json:"services"`
+}
+
+type faradayService struct {
+	Name     string        `json:"name"`
+	Port     int           `json:"port"`
+	Protocol string        `json:"protocol"`
+	Status   string        `json:"status"`
+	Vulns    []faradayVuln `json:"vulnerabilities"`
+}
+
+type faradayVuln struct {
+	Name       string `json:"name"`
+	Desc       string `json:"desc"`
+	Severity   string `json:"severity"`
+	Type       string `json:"type"`
+	Website    string `json:"website"`
+	Path       string `json:"path"`
+	Method     string `json:"method"`
+	ExternalID string `json:"external_id"`
+	Data       string `json:"data"`
+}
+
+func faradaySeverity(severity Severity) string {
+	if severity == SeverityInfo {
+		return "informational"
+	}
+	return severity.String()
+}
+
+func buildFaradayReport() ([]byte, error) {
+	report := faradayReport{
+		Hosts: []*faradayHost{},
+		Command: faradayCommand{
+			Tool:      "cors-scanner",
+			Command:   "cors-scanner",
+			Params:    strings.Join(os.Args[1:], " "),
+			StartDate: time.Now().UTC().Format(time.RFC3339),
+		},
+	}
+	hosts := make(map[string]*faradayHost)
+	services := make(map[string]*faradayService)
+	seen := make(map[string]bool)
+
+	for _, result := range results {
+		risks := assessRisks(result)
+		if len(risks) == 0 {
+			continue
+		}
+		fp := fingerprint(result)
+		if seen[fp] {
+			continue
+		}
+		seen[fp] = true
+
+		parsedURL, err := url.Parse(result.URL)
+		if err != nil {
+			continue
+		}
+		hostname := parsedURL.Hostname()
+		port, _ := strconv.Atoi(parsedURL.Port())
+		if port == 0 {
+			port = 80
+			if parsedURL.Scheme == "https" {
+				port = 443
+			}
+		}
+
+		host, ok := hosts[hostname]
+		if !ok {
+			host = &faradayHost{IP: hostname, Hostnames: []string{hostname}}
+			hosts[hostname] = host
+			report.Hosts = append(report.Hosts, host)
+		}
+		serviceKey := hostname + ":" + strconv.Itoa(port)
+		service, ok := services[serviceKey]
+		if !ok {
+			service = &faradayService{Name: parsedURL.Scheme, Port: port, Protocol: "tcp", Status: "open"}
+			services[serviceKey] = service
+			host.Services = append(host.Services, service)
+		}
+
+		var messages []string
+		for _, risk := range risks {
+			messages = append(messages, risk.Message)
+		}
+		service.Vulns = append(service.Vulns, faradayVuln{
+			Name:       fmt.Sprintf("CORS misconfiguration (%s test)", result.Test),
+			Desc:       strings.Join(messages, "\n"),
+			Severity:   faradaySeverity(maxSeverity(risks)),
+			Type:       "VulnerabilityWeb",
+			Website:    parsedURL.Host,
+			Path:       parsedURL.RequestURI(),
+			Method:     "GET",
+			ExternalID: fp,
+			Data:       findingEvidence(result),
+		})
+	}
+
+	return json.MarshalIndent(report, "", "  ")
+}
+
+func writeReportingPlatformExports() {
+	exports := []struct {
+		name  string
+		file  string
+		build func() ([]byte, error)
+	}{
+		{"Dradis", config.DradisFile, buildDradisProject},
+		{"Faraday", config.FaradayFile, buildFaradayReport},
+		{"SARIF", config.SARIFFile, buildSARIFReport},
+	}
+
+	for _, export := range exports {
+		if export.file == "" {
+			continue
+		}
+		data, err := export.build()
+		if err != nil {
+			log.Printf("Error building %s export: %v", export.name, err)
+			emitError(errOutput, export.file, err)
+			continue
+		}
+		if err := os.WriteFile(export.file, data, 0644); err != nil {
+			log.Printf("Error writing %s export: %v", export.name, err)
+			emitError(errOutput, export.file, err)
+			continue
+		}
+		fmt.Printf("[+] Wrote %s export to %s.\n", export.name, export.file)
+		signOutput(export.file)
+	}
+}
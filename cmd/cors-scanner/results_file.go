@@ -0,0 +1,356 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cors-scanner/pkg/corscan"
+)
+
+var csvHeader = []string{"URL", "Origin", "Test", "Method", "FuzzMethod", "FuzzHeader", "Protocol", "Status", "Vantage", "Class", "Severity", "Verification", "Headless", "ACAO", "ACAC", "ACAM", "ACAH", "ACMA", "ACEH", "ACAPN", "Encoding", "CertSubject", "CertIssuer", "CertSANs", "CertExpiry", "CertError", "Server", "PoweredBy", "Via", "Edge", "CacheControl", "Vary", "Redirects", "Signature", "Cluster", "ScannedAt"}
+
+func csvField(result ScanResult, column string) string {
+	switch column {
+	case "URL":
+		return result.URL
+	case "Origin":
+		return result.Origin
+	case "Test":
+		return result.Test
+	case "Method":
+		return result.Method
+	case "FuzzMethod":
+		return result.FuzzMethod
+	case "FuzzHeader":
+		return result.FuzzHeader
+	case "Protocol":
+		return result.Protocol
+	case "Status":
+		if result.Status == 0 {
+			return ""
+		}
+		return strconv.Itoa(result.Status)
+	case "Vantage":
+		return result.Vantage
+	case "Class":
+		return result.Class
+	case "Severity":
+		return result.Severity.String()
+	case "Verification":
+		return result.Verification
+	case "Headless":
+		return result.Headless
+	case "ACAO":
+		return result.Headers.ACAO
+	case "ACAC":
+		return result.Headers.ACAC
+	case "ACAM":
+		return result.Headers.ACAM
+	case "ACAH":
+		return result.Headers.ACAH
+	case "ACMA":
+		return result.Headers.ACMA
+	case "ACEH":
+		return result.Headers.ACEH
+	case "ACAPN":
+		return result.Headers.ACAPN
+	case "Encoding":
+		return result.Encoding
+	case "CertSubject", "CertIssuer", "CertSANs", "CertExpiry", "CertError":
+		cert := result.Certificate
+		if cert == nil {
+			return ""
+		}
+		switch column {
+		case "CertSubject":
+			return cert.Subject
+		case "CertIssuer":
+			return cert.Issuer
+		case "CertSANs":
+			return strings.Join(cert.SANs, ";")
+		case "CertError":
+			return cert.Error
+		}
+		return cert.NotAfter.Format(time.RFC3339)
+	case "Server", "PoweredBy", "Via", "Edge":
+		tech := result.Tech
+		if tech == nil {
+			return ""
+		}
+		switch column {
+		case "Server":
+			return tech.Server
+		case "PoweredBy":
+			return tech.PoweredBy
+		case "Via":
+			return tech.Via
+		}
+		return strings.Join(tech.Edge, ";")
+	case "Redirects":
+		return formatRedirects(result.Redirects)
+	case "CacheControl", "Vary":
+		cache := result.Cache
+		if cache == nil {
+			return ""
+		}
+		if column == "Vary" {
+			return cache.Vary
+		}
+		return cache.CacheControl
+	case "Signature":
+		return result.Signature
+	case "Cluster":
+		return result.Cluster
+	case "ScannedAt":
+		if result.ScannedAt.IsZero() {
+			return ""
+		}
+		return result.ScannedAt.Format(time.RFC3339)
+	}
+	return ""
+}
+
+// loadResults reads previously saved results. JSON files hold a []ScanResult;
+// CSV files are the ones written by writeCSV and JSONL files the ones
+// streamed with --jsonl.
+func loadResults(path string) ([]ScanResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open results file: %v", err)
+	}
+	defer file.Close()
+
+	var loaded []ScanResult
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		loaded, err = readCSVResults(file, path)
+	case ".jsonl", ".ndjson":
+		loaded, err = readJSONLResults(file, path)
+	default:
+		if err = json.NewDecoder(file).Decode(&loaded); err != nil {
+			err = fmt.Errorf("cannot parse %s: %v", path, err)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Classes follow the current rules, also for results saved before
+	// they were recorded.
+	for i := range loaded {
+		assess(&loaded[i])
+	}
+	return loaded, nil
+}
+
+// readJSONLResults reads one result per line. A scan that crashed may have
+// left its last line incomplete; that line is skipped.
+func readJSONLResults(file *os.File, path string) ([]ScanResult, error) {
+	var loaded []ScanResult
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxBodySize)
+	var pending error
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if pending != nil {
+			return nil, pending
+		}
+		var result ScanResult
+		if err := json.Unmarshal([]byte(text), &result); err != nil {
+			pending = fmt.Errorf("cannot parse %s line %d: %v", path, line, err)
+			continue
+		}
+		loaded = append(loaded, result)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	return loaded, nil
+}
+
+var (
+	resultStream     *os.File
+	resultStreamOnce sync.Once
+	resultStreamMux  sync.Mutex
+)
+
+// streamResult appends a result to --jsonl as one JSON line the moment it
+// is found, so an interrupted scan keeps everything found so far.
+func streamResult(result ScanResult) {
+	if config.JSONLFile == "" {
+		return
+	}
+	resultStreamOnce.Do(func() {
+		file, err := os.OpenFile(config.JSONLFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			log.Printf("Error opening JSONL file: %v", err)
+			emitError(errOutput, config.JSONLFile, err)
+			return
+		}
+		resultStream = file
+	})
+	if resultStream == nil {
+		return
+	}
+
+	result.Risks = assessRisks(result)
+	line, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	resultStreamMux.Lock()
+	defer resultStreamMux.Unlock()
+	if _, err := resultStream.Write(append(line, '\n')); err != nil {
+		log.Printf("Error writing JSONL file: %v", err)
+		emitError(errOutput, config.JSONLFile, err)
+	}
+}
+
+func readCSVResults(file *os.File, path string) ([]ScanResult, error) {
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %v", path, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[name] = i
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	var loaded []ScanResult
+	for _, record := range records[1:] {
+		scannedAt, _ := time.Parse(time.RFC3339, field(record, "ScannedAt"))
+		status, _ := strconv.Atoi(field(record, "Status"))
+		loaded = append(loaded, ScanResult{
+			Result: corscan.Result{
+				URL:      field(record, "URL"),
+				Origin:   field(record, "Origin"),
+				Test:     field(record, "Test"),
+				Method:   field(record, "Method"),
+				Protocol: field(record, "Protocol"),
+				Status:   status,
+				Vantage:  field(record, "Vantage"),
+				Headers: CORSHeaders{
+					ACAO:  field(record, "ACAO"),
+					ACAC:  field(record, "ACAC"),
+					ACAM:  field(record, "ACAM"),
+					ACAH:  field(record, "ACAH"),
+					ACMA:  field(record, "ACMA"),
+					ACEH:  field(record, "ACEH"),
+					ACAPN: field(record, "ACAPN"),
+				},
+				Encoding:  field(record, "Encoding"),
+				ScannedAt: scannedAt,
+			},
+			Verification: field(record, "Verification"),
+			Headless:     field(record, "Headless"),
+			FuzzMethod:   field(record, "FuzzMethod"),
+			FuzzHeader:   field(record, "FuzzHeader"),
+			Certificate:  csvCertificate(func(name string) string { return field(record, name) }),
+			Tech:         csvTech(func(name string) string { return field(record, name) }),
+			Cache:        csvCache(func(name string) string { return field(record, name) }),
+			Redirects:    parseRedirects(field(record, "Redirects")),
+			Signature:    field(record, "Signature"),
+			Cluster:      field(record, "Cluster"),
+		})
+	}
+	return loaded, nil
+}
+
+// csvCertificate rebuilds the certificate columns of a CSV record.
+func csvCertificate(field func(string) string) *CertInfo {
+	if field("CertSubject") == "" && field("CertIssuer") == "" {
+		return nil
+	}
+	notAfter, _ := time.Parse(time.RFC3339, field("CertExpiry"))
+	cert := &CertInfo{Subject: field("CertSubject"), Issuer: field("CertIssuer"), NotAfter: notAfter, Error: field("CertError")}
+	if sans := field("CertSANs"); sans != "" {
+		cert.SANs = strings.Split(sans, ";")
+	}
+	return cert
+}
+
+// csvTech rebuilds the technology columns of a CSV record.
+func csvTech(field func(string) string) *TechInfo {
+	tech := &TechInfo{Server: field("Server"), PoweredBy: field("PoweredBy"), Via: field("Via")}
+	if edge := field("Edge"); edge != "" {
+		tech.Edge = strings.Split(edge, ";")
+	}
+	if tech.Server == "" && tech.PoweredBy == "" && tech.Via == "" && len(tech.Edge) == 0 {
+		return nil
+	}
+	return tech
+}
+
+// csvCache rebuilds the caching columns of a CSV record.
+func csvCache(field func(string) string) *CacheInfo {
+	cache := &CacheInfo{CacheControl: field("CacheControl"), Vary: field("Vary")}
+	if cache.CacheControl == "" && cache.Vary == "" {
+		return nil
+	}
+	return cache
+}
+
+// findResult looks a finding up by fingerprint or by its 1-based position
+// in the results listing.
+func findResult(loaded []ScanResult, id string) (ScanResult, error) {
+	for _, result := range loaded {
+		if fingerprint(result) == id {
+			return result, nil
+		}
+	}
+
+	if index, err := strconv.Atoi(id); err == nil && index >= 1 && index <= len(loaded) {
+		return loaded[index-1], nil
+	}
+	return ScanResult{}, fmt.Errorf("no finding %q in results", id)
+}
+
+// writeJSON writes the results to --json, risks and remediation included,
+// for jq and other tooling.
+func writeJSON() {
+	if config.JSONFile == "" {
+		return
+	}
+	if err := saveResults(config.JSONFile, results); err != nil {
+		log.Printf("Error writing JSON file: %v", err)
+		emitError(errOutput, config.JSONFile, err)
+		return
+	}
+	fmt.Printf("[+] Wrote %d results to %s.\n", len(results), config.JSONFile)
+}
+
+// saveResults writes results as an indented JSON array, the format
+// loadResults reads back.
+func saveResults(path string, list []ScanResult) error {
+	data, err := marshalResults(list)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	signOutput(path)
+	return nil
+}
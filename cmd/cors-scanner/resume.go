@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// checkpointEntry is one line of a --resume state file: a URL whose tests
+// all completed, with the results they produced.
+type checkpointEntry struct {
+	URL     string       `json:"url"`
+	Results []ScanResult `json:"results,omitempty"`
+}
+
+var (
+	checkpointFile *os.File
+	checkpointMux  sync.Mutex
+)
+
+// openCheckpoint reads a --resume state file, restores the results of
+// the URLs it records as completed and opens it for appending. It returns
+// the completed URLs. A missing file starts a new checkpoint; a last line
+// left incomplete by a crash is ignored, so that URL is scanned again.
+func openCheckpoint(path string) (map[string]bool, error) {
+	completed := make(map[string]bool)
+
+	if file, err := os.Open(path); err == nil {
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), maxBodySize)
+		for scanner.Scan() {
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+			var entry checkpointEntry
+			if err := json.Unmarshal([]byte(text), &entry); err != nil || entry.URL == "" {
+				continue
+			}
+			if !completed[entry.URL] {
+				completed[entry.URL] = true
+				appendResults(entry.Results...)
+			}
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("cannot open state file: %v", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open state file: %v", err)
+	}
+	checkpointFile = file
+	return completed, nil
+}
+
+// resumeTargets drops the URLs a previous run completed.
+func resumeTargets(urls []string) ([]string, error) {
+	completed, err := openCheckpoint(config.Resume)
+	if err != nil {
+		return nil, err
+	}
+	if len(completed) == 0 {
+		return urls, nil
+	}
+
+	var remaining []string
+	for _, u := range urls {
+		if !completed[u] {
+			remaining = append(remaining, u)
+		}
+	}
+	fmt.Printf("[+] Resuming from %s: %d URLs already scanned (%d results), %d remaining.\n",
+		config.Resume, len(urls)-len(remaining), len(results), len(remaining))
+	return remaining, nil
+}
+
+// checkpointURL records a URL whose tests have all completed, together
+// with its results, so a restarted scan can skip it.
+func checkpointURL(url string) {
+	if checkpointFile == nil {
+		return
+	}
+	line, err := json.Marshal(checkpointEntry{URL: url, Results: resultsFor(url)})
+	if err != nil {
+		return
+	}
+
+	checkpointMux.Lock()
+	defer checkpointMux.Unlock()
+	if _, err := checkpointFile.Write(append(line, '\n')); err != nil {
+		log.Printf("Error writing state file: %v", err)
+		emitError(errOutput, config.Resume, err)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"cors-scanner/pkg/corscan"
+)
+
+// Severities and risks are shared with the scanning library.
+type (
+	Severity = corscan.Severity
+	Risk     = corscan.Risk
+)
+
+const (
+	SeverityInfo     = corscan.SeverityInfo
+	SeverityLow      = corscan.SeverityLow
+	SeverityMedium   = corscan.SeverityMedium
+	SeverityHigh     = corscan.SeverityHigh
+	SeverityCritical = corscan.SeverityCritical
+)
+
+// extraRisks are the risks of the checks that need more than the CORS
+// headers: the character probe, caching, redirects, spec validation,
+// --fuzz-methods, --header-reflection and --rules.
+func extraRisks(result ScanResult) []Risk {
+	var risks []Risk
+	// The character probe only runs on reflected origins.
+	if result.CharProbe.Unsanitized() {
+		risks = append(risks, Risk{ID: "reflection-unsanitized", Severity: SeverityInfo, Message: "Every probed special character survives into ACAO - the origin is echoed without any sanitisation"})
+	}
+	risks = append(risks, cachePoisoningRisk(result)...)
+	risks = append(risks, redirectRisks(result)...)
+	risks = append(risks, specViolations(result)...)
+	risks = append(risks, fuzzRisks(result)...)
+	risks = append(risks, headerReflectionRisks(result)...)
+	risks = append(risks, result.CustomRisks...)
+	return risks
+}
+
+// assess sets the risks, class and severity of a result, from the
+// library's assessment and the command's own checks.
+func assess(result *ScanResult) {
+	result.Assess(extraRisks(*result)...)
+}
+
+// assessRisks returns the risks of a result, as assess sets them.
+func assessRisks(result ScanResult) []Risk {
+	assess(&result)
+	return result.Risks
+}
+
+func maxSeverity(risks []Risk) Severity {
+	return corscan.MaxSeverity(risks)
+}
+
+// fingerprint identifies a finding across scans. The URL, test, origin,
+// method (with the method a --fuzz-methods preflight asked about), vantage
+// and primary (most severe) risk are hashed; secondary risks can come and
+// go without creating a new finding. Random origins change on every run,
+// so the origin is hashed as corscan.OriginKey; --fuzz-methods and
+// --header-reflection preflights send the origin of the reflected test.
+func fingerprint(result ScanResult) string {
+	primary := ""
+	risks := assessRisks(result)
+	sort.SliceStable(risks, func(i, j int) bool { return risks[i].Severity > risks[j].Severity })
+	if len(risks) > 0 {
+		primary = risks[0].ID
+	}
+
+	method := methodOf(result)
+	if result.FuzzMethod != "" {
+		method += " " + result.FuzzMethod
+	}
+	originTest := result.Test
+	if originTest == testMethodFuzz || originTest == testHeaderReflection {
+		originTest = "reflected"
+	}
+	key := strings.Join([]string{result.URL, result.Test, corscan.OriginKey(originTest, result.Origin), method, result.Vantage, primary}, "|")
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
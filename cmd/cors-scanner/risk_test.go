@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+
+	"cors-scanner/pkg/corscan"
+)
+
+func TestFingerprint(t *testing.T) {
+	finding := func(test, origin, method, vantage string) ScanResult {
+		return ScanResult{Result: corscan.Result{
+			URL:     "https://api.example.com/",
+			Origin:  origin,
+			Test:    test,
+			Method:  method,
+			Vantage: vantage,
+			Headers: CORSHeaders{ACAO: origin, ACAC: "true"},
+		}}
+	}
+	base := finding("regex-bypass", "https://api.example.com`.abcdefghijkl.com", "", "")
+
+	tests := []struct {
+		name  string
+		other ScanResult
+		same  bool
+	}{
+		{"identical", base, true},
+		{"random label of another scan", finding("regex-bypass", "https://api.example.com`.mnopqrstuvwx.com", "", ""), true},
+		{"explicit GET", finding("regex-bypass", "https://api.example.com`.abcdefghijkl.com", "GET", ""), true},
+		{"other bypass character", finding("regex-bypass", "https://api.example.com!.abcdefghijkl.com", "", ""), false},
+		{"other test", finding("tld", "https://api.example.com`.abcdefghijkl.com", "", ""), false},
+		{"other method", finding("regex-bypass", "https://api.example.com`.abcdefghijkl.com", "POST", ""), false},
+		{"other vantage", finding("regex-bypass", "https://api.example.com`.abcdefghijkl.com", "", "eu"), false},
+		{"other primary risk", ScanResult{Result: corscan.Result{URL: base.URL, Origin: base.Origin, Test: base.Test, Headers: CORSHeaders{ACAO: base.Origin}}}, false},
+		{"other URL", ScanResult{Result: corscan.Result{URL: "https://api.example.com/v2", Origin: base.Origin, Test: base.Test, Headers: base.Headers}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := fingerprint(tt.other) == fingerprint(base); same != tt.same {
+				t.Errorf("fingerprint equal = %v, want %v", same, tt.same)
+			}
+		})
+	}
+}
+
+func TestFingerprintReflectedAcrossScans(t *testing.T) {
+	first := ScanResult{Result: corscan.Result{URL: "https://api.example.com/", Origin: "abcdefghijkl.com", Test: "reflected", Headers: CORSHeaders{ACAO: "abcdefghijkl.com"}}}
+	second := first
+	second.Origin, second.Headers.ACAO = "mnopqrstuvwx.com", "mnopqrstuvwx.com"
+	if fingerprint(first) != fingerprint(second) {
+		t.Errorf("fingerprint of the reflected test changes with its random origin")
+	}
+	fuzzed := first
+	fuzzed.Test, fuzzed.Method, fuzzed.FuzzMethod = testMethodFuzz, "OPTIONS", "PUT"
+	fuzzedAgain := fuzzed
+	fuzzedAgain.Origin, fuzzedAgain.Headers.ACAO = second.Origin, second.Headers.ACAO
+	if fingerprint(fuzzed) != fingerprint(fuzzedAgain) {
+		t.Errorf("fingerprint of a fuzzed method changes with its random origin")
+	}
+	if got := len(fingerprint(first)); got != 16 {
+		t.Errorf("fingerprint has %d characters, want 16", got)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cors-scanner/pkg/corscan"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// customRule is a Starlark script loaded with --rules. Its check function
+// is frozen after loading and can be called from every worker.
+type customRule struct {
+	name  string
+	check starlark.Callable
+}
+
+var customRules []customRule
+
+// loadRules loads each script and looks up its check(result) function.
+func loadRules(paths []string) error {
+	for _, path := range paths {
+		thread := &starlark.Thread{Name: path}
+		globals, err := starlark.ExecFile(thread, path, nil, nil)
+		if err != nil {
+			return fmt.Errorf("cannot load rules %s: %v", path, err)
+		}
+		check, ok := globals["check"].(starlark.Callable)
+		if !ok {
+			return fmt.Errorf("rules %s does not define a check(result) function", path)
+		}
+		globals.Freeze()
+		customRules = append(customRules, customRule{
+			name:  strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+			check: check,
+		})
+	}
+	return nil
+}
+
+// evaluateRules runs every custom rule against a result. Each check
+// returns None, one finding or a list of findings; a finding is a dict
+// with "message" and optionally "severity" (default "medium") and "id"
+// (default the script's file name).
+func evaluateRules(result ScanResult) ([]Risk, error) {
+	if len(customRules) == 0 {
+		return nil, nil
+	}
+	arg := ruleInput(result)
+
+	var risks []Risk
+	for _, rule := range customRules {
+		thread := &starlark.Thread{Name: rule.name}
+		out, err := starlark.Call(thread, rule.check, starlark.Tuple{arg}, nil)
+		if err != nil {
+			return risks, fmt.Errorf("rule %s: %v", rule.name, err)
+		}
+
+		var findings []starlark.Value
+		switch v := out.(type) {
+		case starlark.NoneType:
+		case *starlark.List:
+			for i := 0; i < v.Len(); i++ {
+				findings = append(findings, v.Index(i))
+			}
+		default:
+			findings = append(findings, v)
+		}
+
+		for _, finding := range findings {
+			risk, err := ruleRisk(rule.name, finding)
+			if err != nil {
+				return risks, fmt.Errorf("rule %s: %v", rule.name, err)
+			}
+			risks = append(risks, risk)
+		}
+	}
+	return risks, nil
+}
+
+func ruleRisk(ruleName string, finding starlark.Value) (Risk, error) {
+	dict, ok := finding.(*starlark.Dict)
+	if !ok {
+		return Risk{}, fmt.Errorf("finding must be a dict, got %s", finding.Type())
+	}
+	field := func(name string) string {
+		v, found, _ := dict.Get(starlark.String(name))
+		if !found {
+			return ""
+		}
+		if s, ok := starlark.AsString(v); ok {
+			return s
+		}
+		return v.String()
+	}
+
+	risk := Risk{ID: field("id"), Severity: SeverityMedium, Message: field("message")}
+	if risk.ID == "" {
+		risk.ID = ruleName
+	}
+	if risk.Message == "" {
+		return Risk{}, fmt.Errorf("finding has no message")
+	}
+	if name := field("severity"); name != "" {
+		severity, ok := corscan.ParseSeverity(name)
+		if !ok {
+			return Risk{}, fmt.Errorf("unknown severity %q", name)
+		}
+		risk.Severity = severity
+	}
+	return risk, nil
+}
+
+// ruleInput exposes a result to Starlark as a struct with the fields url,
+// origin, test, method, vantage, encoding, headers (the CORS headers by
+// short name) and response_headers (lower-cased names to value lists, only
+// with --capture-headers).
+func ruleInput(result ScanResult) starlark.Value {
+	h := result.Headers
+	headers := starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"acao":  starlark.String(h.ACAO),
+		"acac":  starlark.String(h.ACAC),
+		"acam":  starlark.String(h.ACAM),
+		"acah":  starlark.String(h.ACAH),
+		"acma":  starlark.String(h.ACMA),
+		"aceh":  starlark.String(h.ACEH),
+		"acapn": starlark.String(h.ACAPN),
+	})
+
+	response := starlark.NewDict(len(result.ResponseHeaders))
+	names := make([]string, 0, len(result.ResponseHeaders))
+	for name := range result.ResponseHeaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var values []starlark.Value
+		for _, value := range result.ResponseHeaders[name] {
+			values = append(values, starlark.String(value))
+		}
+		response.SetKey(starlark.String(strings.ToLower(name)), starlark.NewList(values))
+	}
+
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"url":              starlark.String(result.URL),
+		"origin":           starlark.String(result.Origin),
+		"test":             starlark.String(result.Test),
+		"method":           starlark.String(methodOf(result)),
+		"status":           starlark.MakeInt(result.Status),
+		"vantage":          starlark.String(result.Vantage),
+		"encoding":         starlark.String(result.Encoding),
+		"headers":          headers,
+		"response_headers": response,
+	})
+}
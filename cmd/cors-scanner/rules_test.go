@@ -0,0 +1,90 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+const ruleScript = `
+def check(result):
+    if result.headers.acao == "*" and "x-internal" in result.response_headers:
+        return {"message": "internal API open to any origin", "severity": "high"}
+    if result.test == "null" and result.headers.acao == "null":
+        return [{"id": "null-origin", "message": "null allowed"}, {"message": "second finding", "severity": "low"}]
+    return None
+`
+
+func TestEvaluateRules(t *testing.T) {
+	defer func() { customRules = nil }()
+
+	if err := loadRules([]string{writeTemp(t, "internal.star", ruleScript)}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		test    string
+		headers CORSHeaders
+		capture map[string][]string
+		want    []Risk
+	}{
+		{"single finding", "wildcard", CORSHeaders{ACAO: "*"}, map[string][]string{"X-Internal": {"1"}},
+			[]Risk{{ID: "internal", Severity: SeverityHigh, Message: "internal API open to any origin"}}},
+		{"list of findings", "null", CORSHeaders{ACAO: "null"}, nil, []Risk{
+			{ID: "null-origin", Severity: SeverityMedium, Message: "null allowed"},
+			{ID: "internal", Severity: SeverityLow, Message: "second finding"},
+		}},
+		{"no finding", "wildcard", CORSHeaders{ACAO: "*"}, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ScanResult{}
+			result.URL = "https://api.example.com/"
+			result.Test = tt.test
+			result.Headers = tt.headers
+			result.ResponseHeaders = tt.capture
+
+			got, err := evaluateRules(result)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("evaluateRules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateRulesErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+	}{
+		{"not a dict", "def check(result):\n    return \"open\"\n"},
+		{"no message", "def check(result):\n    return {\"severity\": \"high\"}\n"},
+		{"unknown severity", "def check(result):\n    return {\"message\": \"m\", \"severity\": \"dire\"}\n"},
+		{"runtime error", "def check(result):\n    return result.missing\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() { customRules = nil }()
+			if err := loadRules([]string{writeTemp(t, "rule.star", tt.script)}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := evaluateRules(ScanResult{}); err == nil {
+				t.Error("evaluateRules succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestLoadRulesErrors(t *testing.T) {
+	defer func() { customRules = nil }()
+
+	if err := loadRules([]string{writeTemp(t, "syntax.star", "def check(result)\n")}); err == nil {
+		t.Error("loadRules accepted a syntax error")
+	}
+	if err := loadRules([]string{writeTemp(t, "nocheck.star", "x = 1\n")}); err == nil {
+		t.Error("loadRules accepted a script without check")
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"cors-scanner/pkg/corscan"
+)
+
+// SARIF 2.1.0 output for GitHub code scanning and GitLab security
+// dashboards: one rule per test, one result per result with risks.
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Version        string      `json:"version"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifRule struct {
+	ID                   string            `json:"id"`
+	Name                 string            `json:"name"`
+	ShortDescription     sarifText         `json:"shortDescription"`
+	FullDescription      sarifText         `json:"fullDescription"`
+	Help                 sarifText         `json:"help"`
+	DefaultConfiguration sarifRuleConfig   `json:"defaultConfiguration"`
+	Properties           map[string]string `json:"properties"`
+}
+
+type sarifRuleConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             sarifText         `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	WebRequest          *sarifWebRequest  `json:"webRequest,omitempty"`
+	WebResponse         *sarifWebResponse `json:"webResponse,omitempty"`
+	Properties          map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+	} `json:"physicalLocation"`
+}
+
+type sarifWebRequest struct {
+	Method  string            `json:"method"`
+	Target  string            `json:"target"`
+	Headers map[string]string `json:"headers"`
+}
+
+type sarifWebResponse struct {
+	StatusCode int               `json:"statusCode,omitempty"`
+	Headers    map[string]string `json:"headers"`
+}
+
+// sarifTestRules describes each test as a SARIF rule. The security
+// severity (0-10) is what the test finds at worst.
+var sarifTestRules = []struct {
+	test     string
+	name     string
+	short    string
+	full     string
+	advice   string // riskAdvice key for the rule's help text
+	level    string
+	severity string
+}{
+	{"existing", "ExistingPolicy", "CORS policy for the target's own origin",
+		"The CORS headers returned for the target's own origin, which show the configured policy such as a wildcard.", "wildcard-credentials", "warning", "5.0"},
+	{"null", "NullOriginAccepted", "Origin: null accepted",
+		"The target allows the null origin, which sandboxed iframes, data: URLs and local files send, so any attacker can obtain it.", "null-origin", "error", "8.0"},
+	{"reflected", "OriginReflection", "Arbitrary origin reflected",
+		"The target echoes an unrelated attacker-chosen origin in Access-Control-Allow-Origin, allowing any site to read its responses.", "origin-reflection", "error", "8.8"},
+	{"scheme", "SchemeDowngradeTrusted", "Origin with the other scheme trusted",
+		"The target trusts its own host over the other scheme, so a network attacker who can inject into the HTTP site can read HTTPS responses.", "origin-reflection", "warning", "6.5"},
+	{"prefix", "PrefixedOriginTrusted", "Origin with a prefixed host trusted",
+		"The target trusts origins that merely end with its host name, which attackers can register.", "origin-reflection", "error", "8.8"},
+	{"suffix", "SuffixedOriginTrusted", "Origin with a suffixed host trusted",
+		"The target trusts origins that merely start with its host name, which attackers can register.", "origin-reflection", "error", "8.8"},
+	{"regex-bypass", "RegexBypassOriginTrusted", "Look-alike origin trusted",
+		"The target trusts a look-alike of its host, such as one with an unescaped dot or a special character before an attacker's domain, which a naive allowlist regex accepts.", "origin-reflection", "error", "8.8"},
+	{"tld", "SwappedTLDOriginTrusted", "Origin under another top-level domain trusted",
+		"The target trusts its host under another top-level domain, or its domain followed by an attacker's, which startsWith or unanchored checks accept.", "origin-reflection", "error", "8.8"},
+	{"subdomain", "AnySubdomainTrusted", "Any subdomain trusted",
+		"The target trusts any subdomain of its domain, so XSS or a takeover on one subdomain lets an attacker read its responses.", "origin-reflection", "warning", "6.5"},
+	{"localhost", "LocalOriginTrusted", "Localhost origin trusted",
+		"The target trusts a localhost or loopback origin, usually a leftover development allowlist entry, so any web app running on a victim's machine can read its responses.", "origin-reflection", "warning", "5.3"},
+	{"internal", "InternalOriginTrusted", "Internal origin trusted",
+		"The target trusts a private-network address or intranet host name, so an attacker with a foothold on the internal network, or an SSRF that serves their content, can read its responses.", "origin-reflection", "warning", "5.3"},
+	{"origin-list", "ListedOriginTrusted", "Origin from the origin list trusted",
+		"The target allows an origin from the supplied --origin-list by name. Check that it is one the application should trust.", "origin-reflection", "warning", "6.5"},
+}
+
+func sarifLevel(severity Severity) string {
+	switch {
+	case severity >= SeverityHigh:
+		return "error"
+	case severity >= SeverityMedium:
+		return "warning"
+	}
+	return "note"
+}
+
+func sarifRuleID(test string) string {
+	if test == "" {
+		test = "other"
+	}
+	return "cors/" + test
+}
+
+func buildSARIFReport() ([]byte, error) {
+	driver := sarifDriver{
+		Name:           "cors-scanner",
+		InformationURI: "https://github.com/Habib0x0/cors-go",
+		Version:        "1.0",
+	}
+	known := make(map[string]bool)
+	for _, r := range sarifTestRules {
+		known[sarifRuleID(r.test)] = true
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   sarifRuleID(r.test),
+			Name:                 r.name,
+			ShortDescription:     sarifText{r.short},
+			FullDescription:      sarifText{r.full},
+			Help:                 sarifText{corscan.Advice(r.advice)},
+			DefaultConfiguration: sarifRuleConfig{Level: r.level},
+			Properties:           map[string]string{"security-severity": r.severity, "tags": "security,cors"},
+		})
+	}
+
+	run := sarifRun{Results: []sarifResult{}}
+	for _, result := range results {
+		risks := assessRisks(result)
+		if len(risks) == 0 {
+			continue
+		}
+
+		ruleID := sarifRuleID(result.Test)
+		if !known[ruleID] {
+			known[ruleID] = true
+			driver.Rules = append(driver.Rules, sarifRule{
+				ID:                   ruleID,
+				Name:                 "CORSMisconfiguration",
+				ShortDescription:     sarifText{"CORS misconfiguration"},
+				FullDescription:      sarifText{"A CORS misconfiguration found by the " + result.Test + " test."},
+				Help:                 sarifText{corscan.Advice("origin-reflection")},
+				DefaultConfiguration: sarifRuleConfig{Level: "warning"},
+				Properties:           map[string]string{"security-severity": "5.0", "tags": "security,cors"},
+			})
+		}
+
+		var messages []string
+		for _, risk := range risks {
+			messages = append(messages, risk.Message)
+		}
+		if remediation := resultRemediation(result); remediation != nil {
+			messages = append(messages, "Fix: "+strings.Join(remediation.Advice, " "))
+		}
+
+		entry := sarifResult{
+			RuleID:              ruleID,
+			Level:               sarifLevel(result.Severity),
+			Message:             sarifText{strings.Join(messages, "\n")},
+			PartialFingerprints: map[string]string{"corsFinding/v1": fingerprint(result)},
+			WebRequest: &sarifWebRequest{
+				Method:  methodOf(result),
+				Target:  result.URL,
+				Headers: map[string]string{"Origin": result.Origin},
+			},
+			WebResponse: &sarifWebResponse{StatusCode: result.Status, Headers: map[string]string{}},
+			Properties:  map[string]string{"class": result.Class, "severity": result.Severity.String()},
+		}
+		if result.Verification != "" {
+			entry.Properties["verification"] = result.Verification
+		}
+		if result.Headless != "" {
+			entry.Properties["headless"] = result.Headless
+		}
+		var location sarifLocation
+		location.PhysicalLocation.ArtifactLocation.URI = result.URL
+		entry.Locations = []sarifLocation{location}
+		for _, row := range corsHeaderRows(result.Headers) {
+			entry.WebResponse.Headers[row[0]] = row[1]
+		}
+		run.Results = append(run.Results, entry)
+	}
+	run.Tool = sarifTool{Driver: driver}
+
+	return json.MarshalIndent(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+}
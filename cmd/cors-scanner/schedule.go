@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// scanWindow is a daily time range during which testing is allowed. The
+// range may wrap past midnight, e.g. 22:00-06:00.
+type scanWindow struct {
+	start, end int // minutes after midnight
+	loc        *time.Location
+}
+
+var (
+	window      *scanWindow
+	windowMux   sync.Mutex
+	windowPause time.Time
+)
+
+func parseScanWindow(spec, zone string) (*scanWindow, error) {
+	parts := strings.Split(spec, "-")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid scan window %q, expected HH:MM-HH:MM", spec)
+	}
+
+	loc := time.Local
+	if zone != "" {
+		var err error
+		if loc, err = time.LoadLocation(zone); err != nil {
+			return nil, fmt.Errorf("invalid scan window timezone: %v", err)
+		}
+	}
+
+	w := &scanWindow{loc: loc}
+	for i, part := range parts {
+		t, err := time.Parse("15:04", strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("invalid scan window time %q, expected HH:MM", part)
+		}
+		minutes := t.Hour()*60 + t.Minute()
+		if i == 0 {
+			w.start = minutes
+		} else {
+			w.end = minutes
+		}
+	}
+	if w.start == w.end {
+		return nil, fmt.Errorf("scan window %q is empty", spec)
+	}
+	return w, nil
+}
+
+func (w *scanWindow) contains(t time.Time) bool {
+	t = t.In(w.loc)
+	m := t.Hour()*60 + t.Minute()
+	if w.start < w.end {
+		return m >= w.start && m < w.end
+	}
+	return m >= w.start || m < w.end
+}
+
+// nextStart returns the next time the window opens after t.
+func (w *scanWindow) nextStart(t time.Time) time.Time {
+	t = t.In(w.loc)
+	start := time.Date(t.Year(), t.Month(), t.Day(), w.start/60, w.start%60, 0, 0, w.loc)
+	if !start.After(t) {
+		start = start.AddDate(0, 0, 1)
+	}
+	return start
+}
+
+// waitForScanWindow blocks while the current time is outside --scan-window.
+// Requests already in flight finish; new ones wait for the next window.
+func waitForScanWindow(ctx context.Context) error {
+	if window == nil {
+		return nil
+	}
+
+	for {
+		now := time.Now()
+		if window.contains(now) {
+			return nil
+		}
+
+		resume := window.nextStart(now)
+		windowMux.Lock()
+		if !windowPause.Equal(resume) {
+			windowPause = resume
+			fmt.Printf("\n[*] Outside scan window, pausing until %s.\n", resume.Format("2006-01-02 15:04 MST"))
+		}
+		windowMux.Unlock()
+
+		if err := sleepContext(ctx, time.Until(resume)); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScanWindow(t *testing.T) {
+	tests := []struct {
+		name       string
+		spec, zone string
+		start, end int
+		wantErr    bool
+	}{
+		{name: "daytime", spec: "09:00-17:30", start: 9 * 60, end: 17*60 + 30},
+		{name: "spaces around times", spec: " 22:00 - 06:00 ", start: 22 * 60, end: 6 * 60},
+		{name: "timezone", spec: "01:00-05:00", zone: "UTC", start: 60, end: 5 * 60},
+		{name: "one time", spec: "09:00", wantErr: true},
+		{name: "three times", spec: "09:00-12:00-17:00", wantErr: true},
+		{name: "bad time", spec: "9am-5pm", wantErr: true},
+		{name: "hour out of range", spec: "09:00-25:00", wantErr: true},
+		{name: "empty window", spec: "10:00-10:00", wantErr: true},
+		{name: "unknown timezone", spec: "09:00-17:00", zone: "Mars/Olympus", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := parseScanWindow(tt.spec, tt.zone)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseScanWindow(%q, %q) succeeded, want an error", tt.spec, tt.zone)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseScanWindow(%q, %q): %v", tt.spec, tt.zone, err)
+			}
+			if w.start != tt.start || w.end != tt.end {
+				t.Errorf("parseScanWindow(%q) = %d-%d, want %d-%d", tt.spec, w.start, w.end, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestScanWindowContains(t *testing.T) {
+	day := &scanWindow{start: 9 * 60, end: 17 * 60, loc: time.UTC}
+	night := &scanWindow{start: 22 * 60, end: 6 * 60, loc: time.UTC}
+	at := func(hour, min int) time.Time { return time.Date(2026, 3, 2, hour, min, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name   string
+		window *scanWindow
+		t      time.Time
+		want   bool
+	}{
+		{"day: at start", day, at(9, 0), true},
+		{"day: inside", day, at(12, 30), true},
+		{"day: at end", day, at(17, 0), false},
+		{"day: before", day, at(8, 59), false},
+		{"night: before midnight", night, at(23, 0), true},
+		{"night: after midnight", night, at(5, 59), true},
+		{"night: at end", night, at(6, 0), false},
+		{"night: midday", night, at(12, 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.window.contains(tt.t); got != tt.want {
+				t.Errorf("contains(%s) = %v, want %v", tt.t.Format("15:04"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanWindowNextStart(t *testing.T) {
+	w := &scanWindow{start: 22 * 60, end: 6 * 60, loc: time.UTC}
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Time
+	}{
+		{"later today", time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC), time.Date(2026, 3, 2, 22, 0, 0, 0, time.UTC)},
+		{"at the start", time.Date(2026, 3, 2, 22, 0, 0, 0, time.UTC), time.Date(2026, 3, 3, 22, 0, 0, 0, time.UTC)},
+		{"tomorrow", time.Date(2026, 3, 2, 23, 0, 0, 0, time.UTC), time.Date(2026, 3, 3, 22, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := w.nextStart(tt.t); !got.Equal(tt.want) {
+				t.Errorf("nextStart(%s) = %s, want %s", tt.t, got, tt.want)
+			}
+		})
+	}
+}
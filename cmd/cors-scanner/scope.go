@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// scopeRules is the engagement scope loaded with --scope. A host is in
+// scope when it matches an include rule and no exclude rule; exclusions
+// always win.
+type scopeRules struct {
+	include, exclude []scopeRule
+	hasCIDR          bool // hostnames must be resolved to check them
+
+	resolved   map[string][]net.IP
+	resolveMux sync.Mutex
+}
+
+// scopeRule is either a domain pattern (exact host or *.domain for
+// subdomains) or a CIDR range.
+type scopeRule struct {
+	domain string
+	net    *net.IPNet
+}
+
+// scope is nil when no scope file was given, allowing everything.
+var scope *scopeRules
+
+// includeRegex and excludeRegex are the --include-regex and
+// --exclude-regex patterns URLs must and must not match; nil when not
+// given.
+var includeRegex, excludeRegex *regexp.Regexp
+
+// compileScopeRegex compiles an --include-regex or --exclude-regex value.
+func compileScopeRegex(flag, pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s: %v", flag, err)
+	}
+	return re, nil
+}
+
+// loadScope reads a scope file with one rule per line. Lines starting
+// with ! are out of scope; # starts a comment.
+//
+//	example.com
+//	*.example.com
+//	10.0.0.0/8
+//	!admin.example.com
+func loadScope(path string) (*scopeRules, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open scope file: %v", err)
+	}
+	defer file.Close()
+
+	s := &scopeRules{resolved: make(map[string][]net.IP)}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.Index(text, "#"); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.ToLower(strings.TrimSpace(text))
+		if text == "" {
+			continue
+		}
+
+		excluded := strings.HasPrefix(text, "!")
+		text = strings.TrimSpace(strings.TrimPrefix(text, "!"))
+
+		var rule scopeRule
+		if strings.Contains(text, "/") {
+			_, ipNet, err := net.ParseCIDR(text)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid CIDR %q", path, line, text)
+			}
+			rule.net = ipNet
+			s.hasCIDR = true
+		} else if ip := net.ParseIP(text); ip != nil {
+			rule.net = &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)}
+			s.hasCIDR = true
+		} else {
+			rule.domain = text
+		}
+
+		if excluded {
+			s.exclude = append(s.exclude, rule)
+		} else {
+			s.include = append(s.include, rule)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading scope file: %v", err)
+	}
+	if len(s.include) == 0 {
+		return nil, fmt.Errorf("scope file %s has no in-scope entries", path)
+	}
+	return s, nil
+}
+
+func (r scopeRule) matches(host string, ips []net.IP) bool {
+	if r.net != nil {
+		for _, ip := range ips {
+			if r.net.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	if strings.HasPrefix(r.domain, "*.") {
+		return strings.HasSuffix(host, r.domain[1:])
+	}
+	return host == r.domain
+}
+
+// Allows reports whether requests to host on port may be sent.
+// Hostnames are resolved like connections are when the scope has CIDR
+// rules, so a name pointing into an excluded range is refused too.
+func (s *scopeRules) Allows(host, port string) bool {
+	if s == nil {
+		return true
+	}
+	host = strings.ToLower(strings.Trim(host, "[]"))
+	ips := s.addresses(host, port)
+
+	for _, rule := range s.exclude {
+		if rule.matches(host, ips) {
+			return false
+		}
+	}
+	for _, rule := range s.include {
+		if rule.matches(host, ips) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *scopeRules) addresses(host, port string) []net.IP {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}
+	}
+	if !s.hasCIDR {
+		return nil
+	}
+
+	// --resolve pins a host per port
+	key := net.JoinHostPort(host, port)
+	s.resolveMux.Lock()
+	defer s.resolveMux.Unlock()
+	ips, ok := s.resolved[key]
+	if !ok {
+		ips, _ = lookupHost(context.Background(), host, port)
+		s.resolved[key] = ips
+	}
+	return ips
+}
+
+// checkScope returns an error when targetURL is out of scope, or fails
+// the --include-regex and --exclude-regex filters.
+func checkScope(targetURL string) error {
+	if includeRegex != nil && !includeRegex.MatchString(targetURL) {
+		return fmt.Errorf("refusing request to %s: %w (not matched by --include-regex)", targetURL, errScopeRefused)
+	}
+	if excludeRegex != nil && excludeRegex.MatchString(targetURL) {
+		return fmt.Errorf("refusing request to %s: %w (matched by --exclude-regex)", targetURL, errScopeRefused)
+	}
+	if scope == nil {
+		return nil
+	}
+	parsed, err := url.Parse(targetURL)
+	if err != nil {
+		return err
+	}
+	port := parsed.Port()
+	if port == "" {
+		port = "80"
+		if parsed.Scheme == "https" {
+			port = "443"
+		}
+	}
+	if !scope.Allows(parsed.Hostname(), port) {
+		return fmt.Errorf("refusing request to %s: %w", parsed.Host, errScopeRefused)
+	}
+	return nil
+}
+
+// inScopeURLs drops out-of-scope targets from an input list, reporting
+// each one.
+func inScopeURLs(urls []string) []string {
+	var kept []string
+	for _, u := range urls {
+		if err := checkScope(u); err != nil {
+			fmt.Printf("[!] Skipping %s: %v\n", u, err)
+			emitError(errOutOfScope, u, err)
+			continue
+		}
+		kept = append(kept, u)
+	}
+	return kept
+}
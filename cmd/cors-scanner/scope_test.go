@@ -0,0 +1,145 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTemp writes content to a file named name in a temporary directory.
+func writeTemp(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestScopeAllows(t *testing.T) {
+	domains, err := loadScope(writeTemp(t, "domains.txt", "# engagement scope\nexample.com\n*.Example.com  # every subdomain\n\n!admin.example.com\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	networks, err := loadScope(writeTemp(t, "networks.txt", "10.0.0.0/8\n192.168.1.10\n!10.0.5.0/24\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		scope *scopeRules
+		host  string
+		want  bool
+	}{
+		{"domain", domains, "example.com", true},
+		{"subdomain", domains, "api.example.com", true},
+		{"host case ignored", domains, "API.Example.COM", true},
+		{"excluded subdomain", domains, "admin.example.com", false},
+		{"look-alike domain", domains, "notexample.com", false},
+		{"other domain", domains, "example.org", false},
+		{"address in range", networks, "10.1.2.3", true},
+		{"single address", networks, "192.168.1.10", true},
+		{"excluded range", networks, "10.0.5.7", false},
+		{"address out of range", networks, "192.168.1.11", false},
+		{"no scope", nil, "anything.example.net", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.scope.Allows(tt.host, "443"); got != tt.want {
+				t.Errorf("Allows(%q) = %v, want %v", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScopeAllowsResolvedHost(t *testing.T) {
+	resolveOverrides = make(map[string]string)
+	defer func() { resolveOverrides = make(map[string]string) }()
+	if err := parseResolve([]string{"app.corp.invalid:443:10.0.5.20", "api.corp.invalid:443:10.9.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	networks, err := loadScope(writeTemp(t, "networks.txt", "10.0.0.0/8\n!10.0.5.0/24\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !networks.Allows("api.corp.invalid", "443") {
+		t.Errorf("host pinned into the range refused")
+	}
+	if networks.Allows("app.corp.invalid", "443") {
+		t.Errorf("host pinned into the excluded range allowed")
+	}
+}
+
+func TestLoadScopeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"invalid CIDR", "10.0.0.0/33\n"},
+		{"only exclusions", "!admin.example.com\n"},
+		{"only comments", "# nothing yet\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadScope(writeTemp(t, "scope.txt", tt.content)); err == nil {
+				t.Errorf("loadScope succeeded, want an error")
+			}
+		})
+	}
+	if _, err := loadScope(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Errorf("loadScope of a missing file succeeded")
+	}
+}
+
+func TestInScopeURLs(t *testing.T) {
+	rules, err := loadScope(writeTemp(t, "scope.txt", "*.example.com\n!admin.example.com\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scope = rules
+	defer func() { scope = nil }()
+
+	urls := []string{"https://api.example.com/v1", "https://admin.example.com/", "http://www.example.org/", "https://cdn.example.com:8443/"}
+	want := []string{"https://api.example.com/v1", "https://cdn.example.com:8443/"}
+	if got := inScopeURLs(urls); !reflect.DeepEqual(got, want) {
+		t.Errorf("inScopeURLs = %q, want %q", got, want)
+	}
+}
+
+func TestScopeRegex(t *testing.T) {
+	tests := []struct {
+		name             string
+		include, exclude string
+		url              string
+		allowed          bool
+	}{
+		{name: "included", include: `^https://api\.`, url: "https://api.example.com/v1", allowed: true},
+		{name: "not included", include: `^https://api\.`, url: "https://www.example.com/", allowed: false},
+		{name: "excluded", exclude: `/logout`, url: "https://api.example.com/logout", allowed: false},
+		{name: "exclusion wins", include: `example\.com`, exclude: `/logout`, url: "https://api.example.com/logout", allowed: false},
+		{name: "no filters", url: "https://www.example.com/", allowed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if includeRegex, err = compileScopeRegex("include-regex", tt.include); err != nil {
+				t.Fatal(err)
+			}
+			if excludeRegex, err = compileScopeRegex("exclude-regex", tt.exclude); err != nil {
+				t.Fatal(err)
+			}
+			defer func() { includeRegex, excludeRegex = nil, nil }()
+
+			if err := checkScope(tt.url); (err == nil) != tt.allowed {
+				t.Errorf("checkScope(%q) = %v, want allowed %v", tt.url, err, tt.allowed)
+			}
+		})
+	}
+
+	if _, err := compileScopeRegex("include-regex", "(unclosed"); err == nil {
+		t.Errorf("compileScopeRegex accepted an invalid pattern")
+	}
+}
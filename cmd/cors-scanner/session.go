@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+
+	"cors-scanner/pkg/corscan"
+)
+
+// sessionJar holds the cookies collected by --prime-session, per host.
+var sessionJar, _ = cookiejar.New(nil)
+
+// primeSession sends a plain GET without an Origin header and keeps the
+// cookies the target sets, redirects included, since many applications
+// only show their credentialed CORS behaviour once a session exists.
+func primeSession(ctx context.Context, targetURL string) {
+	client := buildHTTPClient(config.Proxy)
+	client.Jar = sessionJar
+	resp, err := sendWithRetry(ctx, client, corscan.Request{Method: "GET", URL: targetURL})
+	if err != nil {
+		emitError(requestErrorCode(err), targetURL, err)
+		if config.Verbose {
+			fmt.Printf("Error priming session: %v\n", err)
+		}
+		return
+	}
+	resp.Body.Close()
+
+	if config.Verbose {
+		if parsed, err := url.Parse(targetURL); err == nil {
+			fmt.Printf("Primed %d session cookies for %s\n", len(sessionJar.Cookies(parsed)), targetURL)
+		}
+	}
+}
+
+// addJarCookies adds a jar's cookies for a request's URL, unless a cookie
+// of the same name is already set.
+func addJarCookies(req *http.Request, jar http.CookieJar) {
+	set := make(map[string]bool)
+	for _, c := range req.Cookies() {
+		set[c.Name] = true
+	}
+	for _, c := range jar.Cookies(req.URL) {
+		if !set[c.Name] {
+			req.AddCookie(c)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// stopScan is closed on the first SIGINT or SIGTERM. Workers finish the
+// URL they are testing and take no new ones, so the results collected so
+// far are still printed and written.
+var stopScan = make(chan struct{})
+
+// handleInterrupts stops the scan gracefully on the first signal and exits
+// at once on the second.
+func handleInterrupts() {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		fmt.Println("\n[!] Interrupted: finishing in-flight requests, then writing partial results. Interrupt again to quit immediately.")
+		close(stopScan)
+		<-signals
+		os.Exit(130)
+	}()
+}
+
+// scanContext returns the context the scan's requests run under. Unlike an
+// interrupt, --max-scan-time also aborts the requests in flight, so a run
+// ends close to its deadline.
+func scanContext() (context.Context, context.CancelFunc) {
+	if config.MaxScanTime > 0 {
+		return context.WithTimeout(context.Background(), config.MaxScanTime)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// scanStopped reports whether workers should stop taking new URLs.
+func scanStopped(ctx context.Context) bool {
+	select {
+	case <-stopScan:
+		return true
+	case <-ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// signingKey is the HMAC key read from --sign-key, nil when output files
+// are not signed.
+var signingKey []byte
+
+func loadSigningKey(path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read signing key: %v", err)
+	}
+	signingKey = []byte(strings.TrimSpace(string(data)))
+	if len(signingKey) == 0 {
+		return fmt.Errorf("signing key %s is empty", path)
+	}
+	return nil
+}
+
+func fileHMAC(path string, key []byte) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// signOutput writes a detached HMAC-SHA256 signature next to an output
+// file as <file>.sig, in sha256sum layout. It does nothing without
+// --sign-key.
+func signOutput(path string) {
+	if signingKey == nil {
+		return
+	}
+	sum, err := fileHMAC(path, signingKey)
+	if err != nil {
+		log.Printf("Error signing %s: %v", path, err)
+		emitError(errOutput, path, err)
+		return
+	}
+	line := fmt.Sprintf("%s  %s\n", sum, filepath.Base(path))
+	if err := os.WriteFile(path+".sig", []byte(line), 0644); err != nil {
+		log.Printf("Error writing signature for %s: %v", path, err)
+		emitError(errOutput, path+".sig", err)
+		return
+	}
+	fmt.Printf("[+] Signed %s (%s.sig).\n", path, path)
+}
+
+// resultsDigest is the SHA-256 of the results as saveResults writes them,
+// embedded in reports so a report can be matched to its JSON results file.
+func resultsDigest(list []ScanResult) string {
+	data, err := marshalResults(list)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func marshalResults(list []ScanResult) ([]byte, error) {
+	// Risks are derived, so they are filled in on the way out, as is the
+	// remediation of results saved before it was recorded.
+	annotated := make([]ScanResult, len(list))
+	for i, result := range list {
+		annotated[i] = result
+		annotated[i].Risks = assessRisks(result)
+		annotated[i].Remediation = resultRemediation(result)
+	}
+	data, err := json.MarshalIndent(annotated, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func newVerifySigCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify-sig <file>...",
+		Short: "Check output files against their .sig signatures (needs --sign-key)",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if signingKey == nil {
+				log.Fatal("please specify the signing key (--sign-key)")
+			}
+
+			failed := 0
+			for _, path := range args {
+				sig, err := os.ReadFile(path + ".sig")
+				if err != nil {
+					fmt.Printf("[!] %s: no signature: %v\n", path, err)
+					failed++
+					continue
+				}
+				fields := strings.Fields(string(sig))
+				sum, err := fileHMAC(path, signingKey)
+				if err != nil {
+					fmt.Printf("[!] %s: %v\n", path, err)
+					failed++
+					continue
+				}
+				if len(fields) == 0 || !hmac.Equal([]byte(fields[0]), []byte(sum)) {
+					fmt.Printf("[!] %s: signature mismatch, the file was modified or signed with another key\n", path)
+					failed++
+					continue
+				}
+				fmt.Printf("[+] %s: signature valid\n", path)
+			}
+			if failed > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+}
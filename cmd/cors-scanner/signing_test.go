@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSigningKey(t *testing.T) {
+	defer func() { signingKey = nil }()
+
+	if err := loadSigningKey(writeTemp(t, "key", "  s3cret\n")); err != nil {
+		t.Fatal(err)
+	}
+	if string(signingKey) != "s3cret" {
+		t.Errorf("signingKey = %q, want the trimmed file content", signingKey)
+	}
+	if err := loadSigningKey(writeTemp(t, "empty", "\n")); err == nil {
+		t.Error("loadSigningKey accepted an empty key")
+	}
+	if err := loadSigningKey(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("loadSigningKey accepted a missing file")
+	}
+}
+
+func TestSignOutput(t *testing.T) {
+	defer func() { signingKey = nil }()
+
+	path := writeTemp(t, "results.json", "The quick brown fox jumps over the lazy dog")
+	signOutput(path)
+	if _, err := os.Stat(path + ".sig"); !os.IsNotExist(err) {
+		t.Fatalf("signed without a key: %v", err)
+	}
+
+	signingKey = []byte("key")
+	signOutput(path)
+	sig, err := os.ReadFile(path + ".sig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8  results.json\n"
+	if string(sig) != want {
+		t.Errorf("signature = %q, want %q", sig, want)
+	}
+}
+
+func TestResultsDigest(t *testing.T) {
+	list := []ScanResult{{}}
+	list[0].URL = "https://example.com/"
+	list[0].Origin = "https://evil.com"
+
+	digest := resultsDigest(list)
+	if len(digest) != 64 || digest != resultsDigest(list) {
+		t.Fatalf("resultsDigest = %q, want a stable SHA-256", digest)
+	}
+	list[0].Origin = "null"
+	if resultsDigest(list) == digest {
+		t.Error("resultsDigest did not change with the results")
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// multiLabelSuffixes are common public suffixes with two labels, enough to
+// keep "example.co.uk" from collapsing into "co.uk".
+var multiLabelSuffixes = map[string]bool{
+	"co.uk": true, "org.uk": true, "ac.uk": true, "gov.uk": true,
+	"com.au": true, "net.au": true, "org.au": true,
+	"co.jp": true, "co.nz": true, "co.za": true, "co.in": true,
+	"com.br": true, "com.cn": true, "com.mx": true, "com.tr": true,
+}
+
+// apexDomain returns the registrable domain of a host, or the host itself
+// for IP addresses and single-label names.
+func apexDomain(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if net.ParseIP(strings.Trim(host, "[]")) != nil {
+		return host
+	}
+
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 {
+		return host
+	}
+	keep := 2
+	if len(labels) >= 3 && multiLabelSuffixes[strings.Join(labels[len(labels)-2:], ".")] {
+		keep = 3
+	}
+	if len(labels) < keep {
+		return host
+	}
+	return strings.Join(labels[len(labels)-keep:], ".")
+}
+
+type countEntry struct {
+	Name  string
+	Count int
+}
+
+func topCounts(counts map[string]int, limit int) []countEntry {
+	var entries []countEntry
+	for name, count := range counts {
+		entries = append(entries, countEntry{name, count})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
+}
+
+func newStatsCmd() *cobra.Command {
+	var top int
+
+	cmd := &cobra.Command{
+		Use:   "stats <results-file>...",
+		Short: "Aggregate findings across many result files",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var paths []string
+			for _, pattern := range args {
+				matches, err := filepath.Glob(pattern)
+				if err != nil || len(matches) == 0 {
+					matches = []string{pattern}
+				}
+				paths = append(paths, matches...)
+			}
+			printStats(paths, top)
+		},
+	}
+
+	cmd.Flags().IntVar(&top, "top", 10, "specify how many entries to list in ranked breakdowns")
+
+	return cmd
+}
+
+// scanStats aggregates the findings of several result files.
+type scanStats struct {
+	Files      int
+	Total      int // results, with or without risks
+	Findings   int
+	Severities map[Severity]int
+	Classes    map[string]int // findings per misconfiguration class
+	Apexes     map[string]int
+	Stacks     map[string]int
+	Trend      map[string]map[Severity]int // day -> findings per severity
+}
+
+func collectStats(paths []string) (scanStats, error) {
+	stats := scanStats{
+		Files:      len(paths),
+		Severities: make(map[Severity]int),
+		Classes:    make(map[string]int),
+		Apexes:     make(map[string]int),
+		Stacks:     make(map[string]int),
+		Trend:      make(map[string]map[Severity]int),
+	}
+
+	for _, path := range paths {
+		loaded, err := loadResults(path)
+		if err != nil {
+			return stats, err
+		}
+
+		// Older CSV files carry no timestamps, so date them by the file.
+		var fallbackDay string
+		if info, err := os.Stat(path); err == nil {
+			fallbackDay = info.ModTime().Format("2006-01-02")
+		}
+
+		for _, result := range loaded {
+			stats.Total++
+			risks := assessRisks(result)
+			if len(risks) == 0 {
+				continue
+			}
+			stats.Findings++
+
+			severity := maxSeverity(risks)
+			stats.Severities[severity]++
+			stats.Classes[result.Class]++
+			if parsedURL, err := url.Parse(result.URL); err == nil {
+				stats.Apexes[apexDomain(parsedURL.Host)]++
+			}
+			if stack := result.Tech.Stack(); stack != "" {
+				stats.Stacks[stack]++
+			}
+
+			day := fallbackDay
+			if !result.ScannedAt.IsZero() {
+				day = result.ScannedAt.Format("2006-01-02")
+			}
+			if stats.Trend[day] == nil {
+				stats.Trend[day] = make(map[Severity]int)
+			}
+			stats.Trend[day][severity]++
+		}
+	}
+	return stats, nil
+}
+
+func printStats(paths []string, top int) {
+	stats, err := collectStats(paths)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(strings.Repeat("=", 70))
+	fmt.Printf("CORS STATISTICS - %d results, %d findings across %d files\n", stats.Total, stats.Findings, stats.Files)
+	fmt.Println(strings.Repeat("=", 70))
+
+	fmt.Println("\nFindings per severity:")
+	for s := SeverityCritical; s >= SeverityInfo; s-- {
+		fmt.Printf("    %-10s %d\n", s.Label(), stats.Severities[s])
+	}
+
+	fmt.Println("\nMost common misconfiguration classes:")
+	for _, entry := range topCounts(stats.Classes, top) {
+		fmt.Printf("    %-32s %d\n", entry.Name, entry.Count)
+	}
+
+	fmt.Println("\nMost affected apex domains:")
+	for _, entry := range topCounts(stats.Apexes, top) {
+		fmt.Printf("    %-32s %d\n", entry.Name, entry.Count)
+	}
+
+	if len(stats.Stacks) > 0 {
+		fmt.Println("\nMost affected stacks:")
+		for _, entry := range topCounts(stats.Stacks, top) {
+			fmt.Printf("    %-32s %d\n", entry.Name, entry.Count)
+		}
+	}
+
+	var days []string
+	for day := range stats.Trend {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	fmt.Println("\nTrend over time:")
+	fmt.Printf("    %-12s %8s %8s %8s %8s %8s\n", "Date", "Critical", "High", "Medium", "Low", "Info")
+	for _, day := range days {
+		counts := stats.Trend[day]
+		fmt.Printf("    %-12s %8d %8d %8d %8d %8d\n", day,
+			counts[SeverityCritical], counts[SeverityHigh], counts[SeverityMedium], counts[SeverityLow], counts[SeverityInfo])
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cors-scanner/pkg/corscan"
+)
+
+// writeResults writes results as a JSON results file in dir.
+func writeResults(t *testing.T, dir, name string, results []ScanResult) string {
+	t.Helper()
+	data, err := json.Marshal(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCollectStats(t *testing.T) {
+	day := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	result := func(url, test, origin string, headers CORSHeaders) ScanResult {
+		return ScanResult{Result: corscan.Result{URL: url, Test: test, Origin: origin, Headers: headers, ScannedAt: day}}
+	}
+	// A credentialed reflection carries several risks but is one finding
+	// of one class.
+	reflected := result("https://api.example.com/", "reflected", "evil.com", CORSHeaders{ACAO: "evil.com", ACAC: "true"})
+	wildcard := result("https://cdn.example.com/", "reflected", "evil.com", CORSHeaders{ACAO: "*"})
+	null := result("https://app.other.org/", "null", "null", CORSHeaders{ACAO: "null"})
+	clean := result("https://www.example.com/", "reflected", "evil.com", CORSHeaders{})
+
+	dir := t.TempDir()
+	first := writeResults(t, dir, "first.json", []ScanResult{reflected, wildcard, clean})
+	second := writeResults(t, dir, "second.json", []ScanResult{reflected, null})
+
+	tests := []struct {
+		name     string
+		paths    []string
+		total    int
+		findings int
+		classes  map[string]int
+		apexes   map[string]int
+	}{
+		{
+			name:     "one file",
+			paths:    []string{first},
+			total:    3,
+			findings: 2,
+			classes:  map[string]int{corscan.ClassReflection: 1, corscan.ClassWildcard: 1},
+			apexes:   map[string]int{"example.com": 2},
+		},
+		{
+			name:     "two files",
+			paths:    []string{first, second},
+			total:    5,
+			findings: 4,
+			classes:  map[string]int{corscan.ClassReflection: 2, corscan.ClassWildcard: 1, corscan.ClassNullTrust: 1},
+			apexes:   map[string]int{"example.com": 3, "other.org": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := collectStats(tt.paths)
+			if err != nil {
+				t.Fatalf("collectStats: %v", err)
+			}
+			if stats.Total != tt.total || stats.Findings != tt.findings {
+				t.Errorf("got %d results and %d findings, want %d and %d", stats.Total, stats.Findings, tt.total, tt.findings)
+			}
+			if !equalCounts(stats.Classes, tt.classes) {
+				t.Errorf("classes = %v, want %v", stats.Classes, tt.classes)
+			}
+			if !equalCounts(stats.Apexes, tt.apexes) {
+				t.Errorf("apexes = %v, want %v", stats.Apexes, tt.apexes)
+			}
+			if got := stats.Trend["2026-03-02"]; got == nil {
+				t.Errorf("trend has no entry for the scan day: %v", stats.Trend)
+			}
+		})
+	}
+
+	if _, err := collectStats([]string{filepath.Join(dir, "missing.json")}); err == nil {
+		t.Errorf("collectStats of a missing file succeeded")
+	}
+}
+
+func equalCounts(got, want map[string]int) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for k, v := range want {
+		if got[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// statsdClient emits metrics over UDP in the DogStatsD dialect, which plain
+// StatsD servers also accept (they ignore the tag suffix).
+type statsdClient struct {
+	conn   net.Conn
+	prefix string
+}
+
+var metrics *statsdClient
+
+func newStatsdClient(addr, prefix string) (*statsdClient, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to statsd at %s: %v", addr, err)
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, ".") {
+		prefix += "."
+	}
+	return &statsdClient{conn: conn, prefix: prefix}, nil
+}
+
+func (s *statsdClient) send(name, value, kind string, tags []string) {
+	if s == nil {
+		return
+	}
+	line := s.prefix + name + ":" + value + "|" + kind
+	if len(tags) > 0 {
+		line += "|#" + strings.Join(tags, ",")
+	}
+	// Metrics are best effort; a dropped packet must never affect the scan.
+	s.conn.Write([]byte(line))
+}
+
+func (s *statsdClient) Incr(name string, tags ...string) {
+	s.send(name, "1", "c", tags)
+}
+
+func (s *statsdClient) Timing(name string, d time.Duration, tags ...string) {
+	s.send(name, fmt.Sprintf("%d", d.Milliseconds()), "ms", tags)
+}
+
+func (s *statsdClient) Close() {
+	if s != nil {
+		s.conn.Close()
+	}
+}
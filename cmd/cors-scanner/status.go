@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseStatusPatterns normalises --match-status and --filter-status
+// values: exact codes such as 403, or classes such as 4xx.
+func parseStatusPatterns(flag string, patterns []string) error {
+	for i, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		valid := len(pattern) == 3 && pattern[0] >= '1' && pattern[0] <= '5'
+		if valid && pattern[1:] != "xx" {
+			_, err := strconv.Atoi(pattern)
+			valid = err == nil
+		}
+		if !valid {
+			return fmt.Errorf("invalid --%s value %q: use a status code such as 403 or a class such as 4xx", flag, patterns[i])
+		}
+		patterns[i] = pattern
+	}
+	return nil
+}
+
+func statusMatches(patterns []string, status int) bool {
+	code := strconv.Itoa(status)
+	for _, pattern := range patterns {
+		if pattern == code || (strings.HasSuffix(pattern, "xx") && pattern[0] == code[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// statusWanted reports whether responses with the given status are kept:
+// they match --match-status, when given, and not --filter-status.
+func statusWanted(status int) bool {
+	if len(config.MatchStatus) > 0 && !statusMatches(config.MatchStatus, status) {
+		return false
+	}
+	return !statusMatches(config.FilterStatus, status)
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// targetOptions are per-target overrides read from a structured input
+// file. They apply on top of the global flags: headers override headers
+// of the same name, cookies replace the --cookies values for the target
+// and methods replace the default GET.
+type targetOptions struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Cookies string            `json:"cookies,omitempty"`
+	Token   string            `json:"token,omitempty"`
+	Methods []string          `json:"methods,omitempty"`
+}
+
+// targetOpts maps target URLs to their overrides. It is filled before
+// scanning starts and only read afterwards.
+var targetOpts = make(map[string]*targetOptions)
+
+// inheritTargetOpts keeps the overrides of an input URL reachable once
+// input handling rewrites it (normalising, stripping its query or moving it
+// to another port). Overrides given for the rewritten URL itself win.
+func inheritTargetOpts(raw, target string) {
+	if t := targetOpts[raw]; t != nil && targetOpts[target] == nil {
+		targetOpts[target] = t
+	}
+}
+
+// isStructuredInput reports whether an input file carries per-target
+// overrides rather than one URL per line.
+func isStructuredInput(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson", ".csv":
+		return true
+	}
+	return false
+}
+
+// loadTargetFile reads a JSONL file of targetOptions objects, or a CSV
+// file with a header row naming the columns url, cookies, token and
+// methods (separated by spaces or ;); any column named header:<Name>
+// sets that request header.
+func loadTargetFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open file: %v", err)
+	}
+	defer file.Close()
+
+	var targets []*targetOptions
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		targets, err = readTargetCSV(file)
+	} else {
+		targets, err = readTargetJSONL(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+
+	var urls []string
+	for _, t := range targets {
+		if !strings.HasPrefix(t.URL, "http") {
+			return nil, fmt.Errorf("error reading %s: invalid URL %q", path, t.URL)
+		}
+		for i, method := range t.Methods {
+			t.Methods[i] = strings.ToUpper(method)
+		}
+		targetOpts[t.URL] = t
+		urls = append(urls, t.URL)
+	}
+	return urls, nil
+}
+
+func readTargetJSONL(file *os.File) ([]*targetOptions, error) {
+	var targets []*targetOptions
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		t := &targetOptions{}
+		if err := json.Unmarshal([]byte(text), t); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		targets = append(targets, t)
+	}
+	return targets, scanner.Err()
+}
+
+func readTargetCSV(file *os.File) ([]*targetOptions, error) {
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := records[0]
+	var targets []*targetOptions
+	for _, record := range records[1:] {
+		t := &targetOptions{Headers: make(map[string]string)}
+		for i, value := range record {
+			if i >= len(columns) || value == "" {
+				continue
+			}
+			name := strings.TrimSpace(columns[i])
+			switch strings.ToLower(name) {
+			case "url":
+				t.URL = strings.TrimSpace(value)
+			case "cookies":
+				t.Cookies = value
+			case "token":
+				t.Token = value
+			case "methods":
+				t.Methods = strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == ' ' })
+			default:
+				if header, ok := cutPrefixFold(name, "header:"); ok {
+					t.Headers[strings.TrimSpace(header)] = value
+				}
+			}
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
+}
+
+// parseMethods folds --method into --methods, upper-cased. --data alone
+// makes POST the method, as it does for curl.
+func parseMethods() error {
+	if config.Method != "" {
+		config.Methods = append([]string{config.Method}, config.Methods...)
+	}
+	if len(config.Methods) == 0 && config.Data != "" {
+		config.Methods = []string{"POST"}
+	}
+	for i, method := range config.Methods {
+		method = strings.ToUpper(strings.TrimSpace(method))
+		if method == "" || strings.ContainsAny(method, " \t/:") {
+			return fmt.Errorf("invalid method %q", config.Methods[i])
+		}
+		config.Methods[i] = method
+	}
+	return nil
+}
+
+// methodsFor returns the methods to test targetURL with: GET unless
+// --methods or the target's methods say otherwise. --preflight and
+// --private-network add OPTIONS to every target's methods.
+func methodsFor(targetURL string) []string {
+	methods := []string{"GET"}
+	if len(config.Methods) > 0 {
+		methods = config.Methods
+	}
+	if t := targetOpts[targetURL]; t != nil && len(t.Methods) > 0 {
+		methods = t.Methods
+	}
+	if (config.Preflight || config.PrivateNetwork) && !containsString(methods, "OPTIONS") {
+		methods = append(append([]string{}, methods...), "OPTIONS")
+	}
+	return methods
+}
+
+// preflightHeaders are the headers that make an OPTIONS request a CORS
+// preflight: the method it asks about, with --preflight-headers the
+// non-simple headers the real request would send, and with
+// --private-network Chrome's Private Network Access request.
+func preflightHeaders() [][2]string {
+	method := strings.ToUpper(config.PreflightMethod)
+	if method == "" {
+		method = "GET"
+	}
+	headers := [][2]string{{"Access-Control-Request-Method", method}}
+	if config.PreflightHeaders != "" {
+		headers = append(headers, [2]string{"Access-Control-Request-Headers", config.PreflightHeaders})
+	}
+	if config.PrivateNetwork {
+		headers = append(headers, [2]string{"Access-Control-Request-Private-Network", "true"})
+	}
+	return headers
+}
+
+// requestBody returns the --data body for a request of the given method;
+// GET, HEAD and OPTIONS requests carry none.
+func requestBody(method string) string {
+	switch method {
+	case "GET", "HEAD", "OPTIONS":
+		return ""
+	}
+	return config.Data
+}
+
+// bodyContentType guesses the Content-Type of a request body: JSON when
+// it looks like a JSON object or array, or else a form.
+func bodyContentType(body string) string {
+	if trimmed := strings.TrimSpace(body); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		return "application/json"
+	}
+	return "application/x-www-form-urlencoded"
+}
+
+// authorization returns the Authorization value for a target token. A
+// bare token is sent as a bearer token; "Basic ..." and other schemes are
+// used as given.
+func authorization(token string) string {
+	if strings.Contains(token, " ") {
+		return token
+	}
+	return "Bearer " + token
+}
+
+// credentials returns the Authorization value set by --bearer or --basic,
+// or "" without either.
+func credentials() string {
+	switch {
+	case config.Bearer != "":
+		return "Bearer " + config.Bearer
+	case config.Basic != "":
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(config.Basic))
+	}
+	return ""
+}
+
+// applyTargetOptions sets the per-target headers, token and cookies on a
+// request. It reports whether the target has its own cookies, which
+// replace the --cookies values.
+func applyTargetOptions(req *http.Request, targetURL string) bool {
+	t := targetOpts[targetURL]
+	if t == nil {
+		return false
+	}
+	for name, value := range t.Headers {
+		req.Header.Set(name, value)
+	}
+	if t.Token != "" {
+		req.Header.Set("Authorization", authorization(t.Token))
+	}
+	if t.Cookies != "" {
+		req.Header.Set("Cookie", t.Cookies)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestLoadTargetFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"JSONL", "targets.jsonl", `{"url": "https://api.example.com/me", "headers": {"X-Tenant": "acme"}, "token": "abc123", "methods": ["get", "put"]}
+
+{"url": "https://www.example.com/", "cookies": "session=1"}
+`},
+		{"CSV", "targets.csv", "url,header:X-Tenant,token,methods,cookies\n" +
+			"https://api.example.com/me,acme,abc123,get;put,\n" +
+			"https://www.example.com/,,,,session=1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() { targetOpts = make(map[string]*targetOptions) }()
+
+			urls, err := loadTargetFile(writeTemp(t, tt.file, tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"https://api.example.com/me", "https://www.example.com/"}; !reflect.DeepEqual(urls, want) {
+				t.Errorf("urls = %v, want %v", urls, want)
+			}
+			if got := targetOpts["https://api.example.com/me"].Methods; !reflect.DeepEqual(got, []string{"GET", "PUT"}) {
+				t.Errorf("methods = %v, want [GET PUT]", got)
+			}
+
+			req, _ := http.NewRequest("GET", "https://api.example.com/me", nil)
+			if applyTargetOptions(req, "https://api.example.com/me") {
+				t.Error("target without cookies reported its own cookies")
+			}
+			if req.Header.Get("X-Tenant") != "acme" || req.Header.Get("Authorization") != "Bearer abc123" {
+				t.Errorf("request headers = %v", req.Header)
+			}
+
+			req, _ = http.NewRequest("GET", "https://www.example.com/", nil)
+			if !applyTargetOptions(req, "https://www.example.com/") || req.Header.Get("Cookie") != "session=1" {
+				t.Errorf("target cookies not applied: %v", req.Header)
+			}
+		})
+	}
+}
+
+func TestLoadTargetFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"relative URL", "targets.jsonl", `{"url": "api.example.com/me"}`},
+		{"invalid JSON", "targets.jsonl", `{"url": "https://api.example.com/me",}`},
+		{"ragged CSV", "targets.csv", "url,token\nhttps://api.example.com/me,abc,extra\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() { targetOpts = make(map[string]*targetOptions) }()
+			if _, err := loadTargetFile(writeTemp(t, tt.file, tt.content)); err == nil {
+				t.Error("loadTargetFile succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestInheritTargetOpts(t *testing.T) {
+	defer func() { targetOpts = make(map[string]*targetOptions) }()
+
+	raw := &targetOptions{URL: "https://example.com/a?x=1", Token: "raw"}
+	own := &targetOptions{URL: "https://example.com/b", Token: "own"}
+	targetOpts[raw.URL] = raw
+	targetOpts[own.URL] = own
+
+	inheritTargetOpts(raw.URL, "https://example.com/a")
+	if targetOpts["https://example.com/a"] != raw {
+		t.Error("rewritten URL lost the overrides of its input URL")
+	}
+	inheritTargetOpts(raw.URL, own.URL)
+	if targetOpts[own.URL] != own {
+		t.Error("overrides of the rewritten URL itself were replaced")
+	}
+	inheritTargetOpts("https://example.com/none", "https://example.com/c")
+	if _, ok := targetOpts["https://example.com/c"]; ok {
+		t.Error("URL without overrides gained an entry")
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// TechInfo records the headers that reveal what serves a target, so
+// findings can be grouped by stack.
+type TechInfo struct {
+	Server    string   `json:"server,omitempty"`
+	PoweredBy string   `json:"powered_by,omitempty"`
+	Via       string   `json:"via,omitempty"`
+	Edge      []string `json:"edge,omitempty"` // CDNs and gateways identified from their headers
+}
+
+// edgeSignatures maps response headers that only a given CDN or gateway
+// sets to its name.
+var edgeSignatures = []struct {
+	header string
+	name   string
+}{
+	{"Cf-Ray", "Cloudflare"},
+	{"X-Amz-Cf-Id", "CloudFront"},
+	{"X-Fastly-Request-Id", "Fastly"},
+	{"Fastly-Debug-Digest", "Fastly"},
+	{"Akamai-Grn", "Akamai"},
+	{"X-Akamai-Transformed", "Akamai"},
+	{"X-Azure-Ref", "Azure Front Door"},
+	{"X-Vercel-Id", "Vercel"},
+	{"X-Nf-Request-Id", "Netlify"},
+	{"X-Sucuri-Id", "Sucuri"},
+	{"X-Iinfo", "Imperva"},
+	{"X-Kong-Upstream-Latency", "Kong"},
+	{"X-Kong-Proxy-Latency", "Kong"},
+	{"X-Amzn-Requestid", "AWS API Gateway"},
+	{"X-Envoy-Upstream-Service-Time", "Envoy"},
+}
+
+// edgeServers maps Server and Via products to the edge they identify.
+var edgeServers = map[string]string{
+	"cloudflare":  "Cloudflare",
+	"akamaighost": "Akamai",
+	"cloudfront":  "CloudFront",
+	"varnish":     "Varnish",
+	"kong":        "Kong",
+	"envoy":       "Envoy",
+	"gws":         "Google",
+	"google":      "Google",
+}
+
+// techInfo extracts the technology headers of a response, or nil when it
+// has none.
+func techInfo(resp *http.Response) *TechInfo {
+	info := &TechInfo{
+		Server:    resp.Header.Get("Server"),
+		PoweredBy: resp.Header.Get("X-Powered-By"),
+		Via:       strings.Join(resp.Header.Values("Via"), ", "),
+	}
+
+	edges := make(map[string]bool)
+	for _, sig := range edgeSignatures {
+		if resp.Header.Get(sig.header) != "" {
+			edges[sig.name] = true
+		}
+	}
+	for _, value := range []string{info.Server, info.Via} {
+		for _, token := range strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+			return r == ' ' || r == '/' || r == ',' || r == '(' || r == ')'
+		}) {
+			if name, ok := edgeServers[token]; ok {
+				edges[name] = true
+			}
+		}
+	}
+	for name := range edges {
+		info.Edge = append(info.Edge, name)
+	}
+	sort.Strings(info.Edge)
+
+	if info.Server == "" && info.PoweredBy == "" && info.Via == "" && len(info.Edge) == 0 {
+		return nil
+	}
+	return info
+}
+
+// Stack is the label results are grouped by: the identified edges, or
+// else the Server product, or else the X-Powered-By product.
+func (t *TechInfo) Stack() string {
+	if t == nil {
+		return ""
+	}
+	if len(t.Edge) > 0 {
+		return strings.Join(t.Edge, " + ")
+	}
+	for _, value := range []string{t.Server, t.PoweredBy} {
+		if product := strings.Fields(value); len(product) > 0 {
+			return strings.SplitN(product[0], "/", 2)[0]
+		}
+	}
+	return ""
+}
+
+// Summary renders all recorded headers on one line for reports.
+func (t *TechInfo) Summary() string {
+	var parts []string
+	if len(t.Edge) > 0 {
+		parts = append(parts, "edge: "+strings.Join(t.Edge, ", "))
+	}
+	if t.Server != "" {
+		parts = append(parts, "Server: "+t.Server)
+	}
+	if t.PoweredBy != "" {
+		parts = append(parts, "X-Powered-By: "+t.PoweredBy)
+	}
+	if t.Via != "" {
+		parts = append(parts, "Via: "+t.Via)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// stackFindings counts, per stack, the results that carry risks and how
+// often each risk occurs among them.
+func stackFindings(list []ScanResult) (map[string]int, map[string]map[string]int) {
+	counts := make(map[string]int)
+	risksByStack := make(map[string]map[string]int)
+	for _, result := range list {
+		stack := result.Tech.Stack()
+		risks := assessRisks(result)
+		if stack == "" || len(risks) == 0 {
+			continue
+		}
+		counts[stack]++
+		if risksByStack[stack] == nil {
+			risksByStack[stack] = make(map[string]int)
+		}
+		for _, risk := range risks {
+			risksByStack[stack][risk.ID]++
+		}
+	}
+	return counts, risksByStack
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// throttleBackoff is the first pause for a host that answers 429
+	// without Retry-After; it doubles while the host keeps throttling.
+	throttleBackoff = 5 * time.Second
+	// maxThrottleWait caps both computed backoffs and Retry-After values.
+	maxThrottleWait = 5 * time.Minute
+	// maxThrottleRetries is how often a throttled request is re-sent
+	// before its 429 response is accepted as the result.
+	maxThrottleRetries = 5
+)
+
+// hostThrottle tracks rate limiting seen from one host.
+type hostThrottle struct {
+	until   time.Time     // no requests to the host before this
+	backoff time.Duration // next backoff without Retry-After
+	events  int
+	waited  time.Duration
+}
+
+var (
+	throttles   = make(map[string]*hostThrottle)
+	throttleMux sync.Mutex
+)
+
+func throttleHost(targetURL string) string {
+	if parsed, err := url.Parse(targetURL); err == nil {
+		return parsed.Host
+	}
+	return targetURL
+}
+
+// waitForHost blocks while the target's host is backed off. Requests to
+// other hosts are unaffected.
+func waitForHost(ctx context.Context, targetURL string) error {
+	throttleMux.Lock()
+	t := throttles[throttleHost(targetURL)]
+	var wait time.Duration
+	if t != nil {
+		wait = time.Until(t.until)
+	}
+	throttleMux.Unlock()
+
+	return sleepContext(ctx, wait)
+}
+
+// recordThrottle backs the host off after a rate-limited response and
+// returns how long it will pause.
+func recordThrottle(targetURL string, resp *http.Response) time.Duration {
+	host := throttleHost(targetURL)
+
+	throttleMux.Lock()
+	defer throttleMux.Unlock()
+
+	t := throttles[host]
+	if t == nil {
+		t = &hostThrottle{backoff: throttleBackoff}
+		throttles[host] = t
+	}
+
+	wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"))
+	if !ok {
+		wait = t.backoff
+		t.backoff *= 2
+	}
+	if wait > maxThrottleWait {
+		wait = maxThrottleWait
+	}
+	if t.backoff > maxThrottleWait {
+		t.backoff = maxThrottleWait
+	}
+
+	if until := time.Now().Add(wait); until.After(t.until) {
+		t.until = until
+	}
+	t.events++
+	t.waited += wait
+	return wait
+}
+
+// parseRetryAfter reads a Retry-After value given either in seconds or
+// as an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		wait := time.Until(at)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// isThrottled reports whether a response asks the client to slow down.
+func isThrottled(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusServiceUnavailable && resp.Header.Get("Retry-After") != "")
+}
+
+func printThrottleSummary() {
+	throttleMux.Lock()
+	defer throttleMux.Unlock()
+
+	if len(throttles) == 0 {
+		return
+	}
+
+	hosts := make([]string, 0, len(throttles))
+	for host := range throttles {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	fmt.Println("\n" + strings.Repeat("=", 70))
+	fmt.Printf("RATE LIMITING - %d hosts throttled the scan\n", len(hosts))
+	fmt.Println(strings.Repeat("=", 70))
+	for _, host := range hosts {
+		t := throttles[host]
+		fmt.Printf("    %s: %d throttled responses, backed off %s in total\n", host, t.events, t.waited.Round(time.Second))
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"seconds", "120", 2 * time.Minute, true},
+		{"padded", " 3 ", 3 * time.Second, true},
+		{"past date", "Wed, 21 Oct 2015 07:28:00 GMT", 0, true},
+		{"empty", "", 0, false},
+		{"negative", "-5", 0, false},
+		{"garbage", "soon", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestIsThrottled(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		want       bool
+	}{
+		{"too many requests", http.StatusTooManyRequests, "", true},
+		{"unavailable with Retry-After", http.StatusServiceUnavailable, "10", true},
+		{"unavailable", http.StatusServiceUnavailable, "", false},
+		{"ok", http.StatusOK, "10", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.retryAfter != "" {
+				resp.Header.Set("Retry-After", tt.retryAfter)
+			}
+			if got := isThrottled(resp); got != tt.want {
+				t.Errorf("isThrottled = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordThrottle(t *testing.T) {
+	defer func() { throttles = make(map[string]*hostThrottle) }()
+
+	throttled := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	target := "https://api.example.com/v1/users?page=2"
+
+	// Without Retry-After the backoff doubles per response, up to the cap.
+	want := throttleBackoff
+	for i := 0; i < 10; i++ {
+		if got := recordThrottle(target, throttled); got != want {
+			t.Fatalf("backoff %d = %v, want %v", i+1, got, want)
+		}
+		if want *= 2; want > maxThrottleWait {
+			want = maxThrottleWait
+		}
+	}
+
+	throttled.Header.Set("Retry-After", "7")
+	if got := recordThrottle("https://api.example.com/other", throttled); got != 7*time.Second {
+		t.Errorf("Retry-After wait = %v, want 7s", got)
+	}
+	throttled.Header.Set("Retry-After", "86400")
+	if got := recordThrottle(target, throttled); got != maxThrottleWait {
+		t.Errorf("long Retry-After wait = %v, want the %v cap", got, maxThrottleWait)
+	}
+
+	if host := throttles["api.example.com"]; host == nil || host.events != 12 {
+		t.Errorf("throttles = %v, want 12 events for api.example.com", throttles)
+	}
+	if throttles["www.example.com"] != nil {
+		t.Error("other hosts were backed off")
+	}
+}
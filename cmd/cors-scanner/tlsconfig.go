@@ -0,0 +1,115 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+// tlsVersions are the values --tls-min-version accepts.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var (
+	// caPool holds the system roots plus --ca-cert, nil for the system
+	// roots alone.
+	caPool *x509.CertPool
+	// tlsMinVersion is the --tls-min-version, 0 for Go's default.
+	tlsMinVersion uint16
+	// clientCerts holds the --client-cert presented for mutual TLS.
+	clientCerts []tls.Certificate
+	// caSPKIs are the base64 SHA-256 hashes of the --ca-cert public keys,
+	// the form Chrome's --ignore-certificate-errors-spki-list takes.
+	caSPKIs []string
+)
+
+// loadTLSSettings reads --ca-cert, --tls-min-version and the mTLS client
+// certificate.
+func loadTLSSettings() error {
+	if config.TLSMinVersion != "" {
+		version, ok := tlsVersions[config.TLSMinVersion]
+		if !ok {
+			return fmt.Errorf("unknown TLS version %q (use 1.0, 1.1, 1.2 or 1.3)", config.TLSMinVersion)
+		}
+		tlsMinVersion = version
+	}
+
+	if (config.ClientCert == "") != (config.ClientKey == "") {
+		return fmt.Errorf("--client-cert and --client-key must be given together")
+	}
+	if config.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(config.ClientCert, config.ClientKey)
+		if err != nil {
+			return fmt.Errorf("cannot load client certificate: %v", err)
+		}
+		clientCerts = []tls.Certificate{cert}
+	}
+
+	if config.CACert == "" {
+		return nil
+	}
+	data, err := os.ReadFile(config.CACert)
+	if err != nil {
+		return fmt.Errorf("cannot read CA certificate: %v", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return fmt.Errorf("no PEM certificates found in %s", config.CACert)
+	}
+	caPool = pool
+
+	for rest := data; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if block.Type != "CERTIFICATE" || err != nil {
+			continue
+		}
+		sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		caSPKIs = append(caSPKIs, base64.StdEncoding.EncodeToString(sum[:]))
+	}
+	return nil
+}
+
+// tlsClientConfig is the TLS configuration of every test request.
+// Certificates are verified unless --insecure is given.
+func tlsClientConfig() *tls.Config {
+	return &tls.Config{
+		InsecureSkipVerify: config.Insecure,
+		RootCAs:            caPool,
+		MinVersion:         tlsMinVersion,
+		Certificates:       clientCerts,
+	}
+}
+
+// verifyCertificate checks a certificate accepted under --insecure as a
+// verifying client would, so results still show TLS problems.
+func verifyCertificate(state *tls.ConnectionState, host string) string {
+	certs := state.PeerCertificates
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		DNSName:       host,
+		Roots:         caPool,
+		Intermediates: intermediates,
+	})
+	if err != nil {
+		return err.Error()
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	utls "github.com/refraction-networking/utls"
+)
+
+// tlsHellos are the browser ClientHellos --tls-fingerprint can mimic.
+var tlsHellos = map[string]utls.ClientHelloID{
+	"chrome":  utls.HelloChrome_Auto,
+	"firefox": utls.HelloFirefox_Auto,
+	"safari":  utls.HelloSafari_Auto,
+}
+
+// tlsHello is the ClientHello selected with --tls-fingerprint, nil for
+// Go's own TLS stack.
+var tlsHello *utls.ClientHelloID
+
+func parseTLSFingerprint(name string) error {
+	if name == "" {
+		return nil
+	}
+	hello, ok := tlsHellos[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("unknown TLS fingerprint %q (use chrome, firefox or safari)", name)
+	}
+	tlsHello = &hello
+	return nil
+}
+
+// dialTLSContext opens TLS connections with a browser's ClientHello, so
+// CDNs that fingerprint Go's TLS stack answer as they would a browser.
+// Only http/1.1 is offered in ALPN because the transport cannot speak h2
+// over a custom TLS connection; the rest of the hello is unchanged.
+func dialTLSContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := dialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+
+	spec, err := utls.UTLSIdToSpec(*tlsHello)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	for _, ext := range spec.Extensions {
+		if alpn, ok := ext.(*utls.ALPNExtension); ok {
+			alpn.AlpnProtocols = []string{"http/1.1"}
+		}
+	}
+
+	tlsConfig := &utls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: config.Insecure,
+		RootCAs:            caPool,
+		MinVersion:         tlsMinVersion,
+	}
+	for _, cert := range clientCerts {
+		tlsConfig.Certificates = append(tlsConfig.Certificates, utls.Certificate{
+			Certificate: cert.Certificate,
+			PrivateKey:  cert.PrivateKey,
+			Leaf:        cert.Leaf,
+		})
+	}
+	client := utls.UClient(conn, tlsConfig, utls.HelloCustom)
+	if err := client.ApplyPreset(&spec); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := client.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
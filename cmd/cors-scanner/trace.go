@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	traceFile *os.File
+	traceOnce sync.Once
+	traceMux  sync.Mutex
+)
+
+// tracingTransport writes every request and response it carries to the
+// --trace file exactly as sent and received, bodies included.
+type tracingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqDump, dumpErr := httputil.DumpRequestOut(req, true)
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n### %s %s (%s)\n", strings.Repeat("#", 70), start.UTC().Format(time.RFC3339Nano), req.URL, time.Since(start).Round(time.Millisecond))
+	if dumpErr != nil {
+		fmt.Fprintf(&b, "[request dump failed: %v]\n", dumpErr)
+	} else {
+		b.Write(reqDump)
+	}
+	b.WriteString("\n\n")
+	if err != nil {
+		fmt.Fprintf(&b, "[error: %v]\n", err)
+	} else if respDump, err := httputil.DumpResponse(resp, true); err != nil {
+		fmt.Fprintf(&b, "[response dump failed: %v]\n", err)
+	} else {
+		b.Write(respDump)
+	}
+	b.WriteString("\n\n")
+	writeTrace(b.String())
+
+	return resp, err
+}
+
+// writeTrace appends one exchange to the trace file, creating it on first
+// use.
+func writeTrace(entry string) {
+	traceOnce.Do(func() {
+		var err error
+		traceFile, err = os.OpenFile(config.TraceFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			log.Printf("Error opening trace file: %v", err)
+		}
+	})
+	if traceFile == nil {
+		return
+	}
+
+	traceMux.Lock()
+	defer traceMux.Unlock()
+	if _, err := traceFile.WriteString(entry); err != nil {
+		log.Printf("Error writing trace file: %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// trendScan is one result file of a trend, dated by its earliest result.
+type trendScan struct {
+	Path    string
+	Date    time.Time
+	Results []ScanResult
+}
+
+// trendFinding follows one finding, a risk on a URL, across scans. Fixed
+// is the first scan after LastSeen in which it no longer appeared.
+type trendFinding struct {
+	Host      string
+	URL       string
+	Risk      string
+	Severity  Severity
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Fixed     time.Time
+}
+
+// loadTrendScans reads result files and orders them by scan date. Files
+// without timestamps, like older CSVs, are dated by their modification time.
+func loadTrendScans(paths []string) []trendScan {
+	var scans []trendScan
+	for _, path := range paths {
+		loaded, err := loadResults(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		scan := trendScan{Path: path, Results: loaded}
+		for _, result := range loaded {
+			if !result.ScannedAt.IsZero() && (scan.Date.IsZero() || result.ScannedAt.Before(scan.Date)) {
+				scan.Date = result.ScannedAt
+			}
+		}
+		if scan.Date.IsZero() {
+			if info, err := os.Stat(path); err == nil {
+				scan.Date = info.ModTime()
+			}
+		}
+		scans = append(scans, scan)
+	}
+	sort.SliceStable(scans, func(i, j int) bool { return scans[i].Date.Before(scans[j].Date) })
+	return scans
+}
+
+func resultHost(result ScanResult) string {
+	if parsedURL, err := url.Parse(result.URL); err == nil && parsedURL.Host != "" {
+		return parsedURL.Host
+	}
+	return result.URL
+}
+
+// trackFindings follows every finding through the scans in order. Each
+// scan is assumed to cover the same targets, so a finding missing from a
+// later scan counts as fixed; one that reappears is open again.
+func trackFindings(scans []trendScan) []*trendFinding {
+	byKey := make(map[string]*trendFinding)
+	var findings []*trendFinding
+
+	for _, scan := range scans {
+		present := make(map[string]bool)
+		for _, result := range scan.Results {
+			for _, risk := range assessRisks(result) {
+				key := result.URL + "\x00" + risk.ID
+				present[key] = true
+				finding := byKey[key]
+				if finding == nil {
+					finding = &trendFinding{Host: resultHost(result), URL: result.URL, Risk: risk.ID, FirstSeen: scan.Date}
+					byKey[key] = finding
+					findings = append(findings, finding)
+				}
+				if risk.Severity > finding.Severity {
+					finding.Severity = risk.Severity
+				}
+				finding.LastSeen = scan.Date
+				finding.Fixed = time.Time{}
+			}
+		}
+		for key, finding := range byKey {
+			if !present[key] && finding.Fixed.IsZero() {
+				finding.Fixed = scan.Date
+			}
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Host != findings[j].Host {
+			return findings[i].Host < findings[j].Host
+		}
+		if findings[i].URL != findings[j].URL {
+			return findings[i].URL < findings[j].URL
+		}
+		return findings[i].Risk < findings[j].Risk
+	})
+	return findings
+}
+
+// hostPolicies summarises, per scan, the origins each host allowed, so a
+// policy change shows up even when the set of findings stays the same.
+func hostPolicies(scans []trendScan) map[string][]string {
+	policies := make(map[string][]string)
+	for i, scan := range scans {
+		allowed := make(map[string]map[string]bool)
+		for _, result := range scan.Results {
+			host := resultHost(result)
+			if allowed[host] == nil {
+				allowed[host] = make(map[string]bool)
+			}
+			if result.Headers.ACAO != "" {
+				value := result.Headers.ACAO
+				if strings.EqualFold(result.Headers.ACAC, "true") {
+					value += " (credentials)"
+				}
+				allowed[host][value] = true
+			}
+		}
+		for host, values := range allowed {
+			if policies[host] == nil {
+				policies[host] = make([]string, len(scans))
+			}
+			var list []string
+			for value := range values {
+				list = append(list, value)
+			}
+			sort.Strings(list)
+			policies[host][i] = strings.Join(list, ", ")
+		}
+	}
+	return policies
+}
+
+func formatTrendDate(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("2006-01-02")
+}
+
+func printTrend(scans []trendScan, findings []*trendFinding) {
+	open := 0
+	for _, finding := range findings {
+		if finding.Fixed.IsZero() {
+			open++
+		}
+	}
+
+	fmt.Println(strings.Repeat("=", 70))
+	fmt.Printf("CORS TREND - %d scans, %d findings (%d open, %d fixed)\n", len(scans), len(findings), open, len(findings)-open)
+	fmt.Println(strings.Repeat("=", 70))
+
+	fmt.Println("\nScans:")
+	for _, scan := range scans {
+		fmt.Printf("    %s  %-40s %d results\n", formatTrendDate(scan.Date), scan.Path, len(scan.Results))
+	}
+
+	policies := hostPolicies(scans)
+	host := ""
+	for _, finding := range findings {
+		if finding.Host != host {
+			host = finding.Host
+			fmt.Printf("\n%s\n", host)
+			for i, policy := range policies[host] {
+				if i > 0 && policy == policies[host][i-1] {
+					continue
+				}
+				if policy == "" {
+					policy = "none recorded"
+				}
+				fmt.Printf("    %s  allowed: %s\n", formatTrendDate(scans[i].Date), policy)
+			}
+		}
+		status := "OPEN"
+		if !finding.Fixed.IsZero() {
+			status = "fixed " + formatTrendDate(finding.Fixed)
+		}
+		fmt.Printf("    [%s] %s %s - first seen %s, last seen %s, %s\n", finding.Severity.Label(), finding.Risk, finding.URL,
+			formatTrendDate(finding.FirstSeen), formatTrendDate(finding.LastSeen), status)
+	}
+}
+
+func renderMarkdownTrend(scans []trendScan, findings []*trendFinding) string {
+	var b strings.Builder
+	b.WriteString("# CORS Trend Report\n\n")
+	fmt.Fprintf(&b, "Generated %s — %d scans from %s to %s.\n\n", time.Now().Format(time.RFC1123), len(scans),
+		formatTrendDate(scans[0].Date), formatTrendDate(scans[len(scans)-1].Date))
+
+	b.WriteString("| Host | URL | Risk | Severity | First seen | Last seen | Fixed |\n")
+	b.WriteString("|------|-----|------|----------|------------|-----------|-------|\n")
+	for _, finding := range findings {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s | %s |\n", finding.Host, finding.URL, finding.Risk, finding.Severity.Label(),
+			formatTrendDate(finding.FirstSeen), formatTrendDate(finding.LastSeen), formatTrendDate(finding.Fixed))
+	}
+
+	policies := hostPolicies(scans)
+	var hosts []string
+	for host := range policies {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		fmt.Fprintf(&b, "\n## %s\n\n| Scan | Allowed origins |\n|------|-----------------|\n", host)
+		for i, policy := range policies[host] {
+			if policy == "" {
+				policy = "—"
+			}
+			fmt.Fprintf(&b, "| %s | %s |\n", formatTrendDate(scans[i].Date), policy)
+		}
+	}
+	return b.String()
+}
+
+func newTrendCmd() *cobra.Command {
+	var markdown string
+
+	cmd := &cobra.Command{
+		Use:   "trend <results-file>...",
+		Short: "Show how findings evolved across repeated scans (first seen, last seen, fixed)",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var paths []string
+			for _, pattern := range args {
+				matches, err := filepath.Glob(pattern)
+				if err != nil || len(matches) == 0 {
+					matches = []string{pattern}
+				}
+				paths = append(paths, matches...)
+			}
+
+			scans := loadTrendScans(paths)
+			findings := trackFindings(scans)
+			printTrend(scans, findings)
+
+			if markdown != "" {
+				if err := os.WriteFile(markdown, []byte(renderMarkdownTrend(scans, findings)), 0644); err != nil {
+					log.Fatalf("Error writing trend report: %v", err)
+				}
+				fmt.Printf("\n[+] Trend report written to %s.\n", markdown)
+				signOutput(markdown)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&markdown, "markdown", "", "specify a Markdown trend report file to write")
+
+	return cmd
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// specViolations checks the returned CORS header values against the Fetch
+// standard and returns one risk per violation found.
+func specViolations(result ScanResult) []Risk {
+	var risks []Risk
+	headers := result.Headers
+
+	origins := headers.ACAOValues
+	if len(origins) == 0 && headers.ACAO != "" {
+		origins = []string{headers.ACAO}
+	}
+
+	wildcard := false
+	for _, origin := range origins {
+		if origin == "*" {
+			wildcard = true
+		}
+	}
+	if wildcard && len(origins) > 1 {
+		risks = append(risks, Risk{ID: "spec-wildcard-mixed", Severity: SeverityLow, Message: "Wildcard mixed with explicit origins in Access-Control-Allow-Origin"})
+	}
+
+	for _, origin := range origins {
+		if hasIllegalChars(origin) {
+			risks = append(risks, Risk{ID: "spec-illegal-chars", Severity: SeverityLow, Message: fmt.Sprintf("Illegal characters in Access-Control-Allow-Origin value %q", origin)})
+			continue
+		}
+		// An echoed probe origin is malformed because the probe was; that
+		// is reported as reflection, not as a serialization problem.
+		if origin == "*" || origin == "null" || origin == result.Origin {
+			continue
+		}
+		if !isSerializedOrigin(origin) {
+			risks = append(risks, Risk{ID: "spec-invalid-origin", Severity: SeverityLow, Message: fmt.Sprintf("Access-Control-Allow-Origin %q is not a valid serialized origin (scheme://host[:port])", origin)})
+		}
+	}
+
+	if headers.ACAC != "" && headers.ACAC != "true" {
+		risks = append(risks, Risk{ID: "spec-credentials-value", Severity: SeverityInfo, Message: fmt.Sprintf("Access-Control-Allow-Credentials is %q; the only valid value is \"true\"", headers.ACAC)})
+	}
+
+	if headers.ACMA != "" {
+		if maxAge, err := strconv.Atoi(strings.TrimSpace(headers.ACMA)); err != nil || maxAge < 0 {
+			risks = append(risks, Risk{ID: "spec-max-age", Severity: SeverityInfo, Message: fmt.Sprintf("Access-Control-Max-Age %q is not a non-negative integer", headers.ACMA)})
+		}
+	}
+
+	for _, list := range []struct{ name, value string }{
+		{"Access-Control-Allow-Methods", headers.ACAM},
+		{"Access-Control-Allow-Headers", headers.ACAH},
+		{"Access-Control-Expose-Headers", headers.ACEH},
+	} {
+		for _, item := range strings.Split(list.value, ";") {
+			item = strings.TrimSpace(item)
+			if item != "" && !isToken(item) {
+				risks = append(risks, Risk{ID: "spec-invalid-token", Severity: SeverityInfo, Message: fmt.Sprintf("%s contains invalid token %q", list.name, item)})
+			}
+		}
+	}
+
+	return risks
+}
+
+// isSerializedOrigin reports whether s is scheme "://" host [ ":" port ]
+// with nothing else, as browsers compare it byte for byte.
+func isSerializedOrigin(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return false
+	}
+	return u.Scheme+"://"+u.Host == s && u.User == nil && strings.ToLower(s) == s
+}
+
+func hasIllegalChars(s string) bool {
+	for _, r := range s {
+		if r < 0x21 || r == 0x7f || r > 0x7e {
+			return true
+		}
+	}
+	return false
+}
+
+// isToken reports whether s is an RFC 7230 token (or the "*" wildcard).
+func isToken(s string) bool {
+	if s == "*" {
+		return true
+	}
+	for _, r := range s {
+		if r > 0x7e || r <= 0x20 || strings.ContainsRune("\"(),/:;<=>?@[\\]{}", r) {
+			return false
+		}
+	}
+	return true
+}
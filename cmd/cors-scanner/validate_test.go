@@ -0,0 +1,44 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"cors-scanner/pkg/corscan"
+)
+
+func TestSpecViolations(t *testing.T) {
+	tests := []struct {
+		name    string
+		origin  string
+		headers CORSHeaders
+		want    []string
+	}{
+		{"valid policy", "https://evil.example.net", CORSHeaders{ACAO: "https://app.example.com", ACAC: "true", ACAM: "GET;PUT", ACMA: "600"}, nil},
+		{"wildcard", "https://evil.example.net", CORSHeaders{ACAO: "*"}, nil},
+		{"null", "null", CORSHeaders{ACAO: "null"}, nil},
+		{"echoed probe origin", "evil.com", CORSHeaders{ACAO: "evil.com"}, nil},
+		{"not an origin", "https://evil.example.net", CORSHeaders{ACAO: "app.example.com"}, []string{"spec-invalid-origin"}},
+		{"origin with a path", "https://evil.example.net", CORSHeaders{ACAO: "https://app.example.com/"}, []string{"spec-invalid-origin"}},
+		{"upper-case origin", "https://evil.example.net", CORSHeaders{ACAO: "https://App.example.com"}, []string{"spec-invalid-origin"}},
+		{"illegal characters", "https://evil.example.net", CORSHeaders{ACAO: "https://app.example.com\x01"}, []string{"spec-illegal-chars"}},
+		{"wildcard mixed with origins", "https://evil.example.net", CORSHeaders{ACAO: "*", ACAOValues: []string{"*", "https://app.example.com"}}, []string{"spec-wildcard-mixed"}},
+		{"credentials value", "https://evil.example.net", CORSHeaders{ACAO: "https://app.example.com", ACAC: "True"}, []string{"spec-credentials-value"}},
+		{"negative max age", "https://evil.example.net", CORSHeaders{ACMA: "-1"}, []string{"spec-max-age"}},
+		{"max age not a number", "https://evil.example.net", CORSHeaders{ACMA: "1h"}, []string{"spec-max-age"}},
+		{"invalid method token", "https://evil.example.net", CORSHeaders{ACAM: "GET;PO ST"}, []string{"spec-invalid-token"}},
+		{"invalid header token", "https://evil.example.net", CORSHeaders{ACAH: "X-Api-Key;X(Bad)", ACEH: "*"}, []string{"spec-invalid-token"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ScanResult{Result: corscan.Result{URL: "https://api.example.com/", Origin: tt.origin, Headers: tt.headers}}
+			var got []string
+			for _, risk := range specViolations(result) {
+				got = append(got, risk.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("specViolations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
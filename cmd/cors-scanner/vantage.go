@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// vantage is a named network position (usually a proxy in another region
+// or network) that every test request is sent through.
+type vantage struct {
+	Name  string
+	Proxy string
+}
+
+var vantages []vantage
+
+func parseVantages(specs []string) ([]vantage, error) {
+	var parsed []vantage
+	seen := make(map[string]bool)
+
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid vantage %q, expected name=proxyurl", spec)
+		}
+		if seen[parts[0]] {
+			return nil, fmt.Errorf("duplicate vantage name %q", parts[0])
+		}
+		if _, err := parseProxy(parts[1]); err != nil {
+			return nil, fmt.Errorf("vantage %s: %v", parts[0], err)
+		}
+		seen[parts[0]] = true
+		parsed = append(parsed, vantage{Name: parts[0], Proxy: parts[1]})
+	}
+
+	return parsed, nil
+}
+
+// vantagePoints returns the vantage points to test through. Without any
+// --vantage flags this is a single unnamed point using --proxy.
+func vantagePoints() []vantage {
+	if len(vantages) == 0 {
+		return []vantage{{Proxy: config.Proxy}}
+	}
+	return vantages
+}
+
+// vantageProxy returns the proxy of the named vantage point, --proxy for
+// results without one.
+func vantageProxy(name string) string {
+	for _, v := range vantages {
+		if v.Name == name {
+			return v.Proxy
+		}
+	}
+	return config.Proxy
+}
+
+// printVantageDiff reports every URL and test whose CORS policy was not the
+// same from all vantage points.
+func printVantageDiff() {
+	if len(vantages) < 2 {
+		return
+	}
+
+	// Origins are generated once per URL and sent from every vantage
+	// point, so URL, test and origin identify the same request; tests can
+	// send several origins.
+	policies := make(map[string]map[string]string)
+	var keys []string
+	for _, result := range results {
+		key := result.URL + " [" + result.Test + "] " + result.Origin
+		if method := methodOf(result); method != "GET" {
+			key += " " + method
+		}
+		if result.FuzzMethod != "" {
+			key += " " + result.FuzzMethod
+		}
+		if policies[key] == nil {
+			policies[key] = make(map[string]string)
+			keys = append(keys, key)
+		}
+		policies[key][result.Vantage] = fmt.Sprintf("ACAO=%q ACAC=%q", result.Headers.ACAO, result.Headers.ACAC)
+	}
+	sort.Strings(keys)
+
+	fmt.Println("\n" + strings.Repeat("=", 70))
+	fmt.Println("VANTAGE POINT COMPARISON")
+	fmt.Println(strings.Repeat("=", 70))
+
+	differing := 0
+	for _, key := range keys {
+		distinct := make(map[string]bool)
+		for _, v := range vantages {
+			policy, ok := policies[key][v.Name]
+			if !ok {
+				policy = "no CORS headers"
+			}
+			distinct[policy] = true
+		}
+		if len(distinct) < 2 {
+			continue
+		}
+
+		differing++
+		fmt.Printf("\n%s\n", key)
+		for _, v := range vantages {
+			policy, ok := policies[key][v.Name]
+			if !ok {
+				policy = "no CORS headers"
+			}
+			fmt.Printf("    %-15s %s\n", v.Name+":", policy)
+		}
+	}
+
+	if differing == 0 {
+		fmt.Println("\n[*] CORS policies were identical from all vantage points.")
+	} else {
+		fmt.Printf("\n[!] %d policies differ between vantage points.\n", differing)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"cors-scanner/pkg/corscan"
+	"github.com/spf13/cobra"
+)
+
+func newVerifyCmd() *cobra.Command {
+	var input, findingID string
+	var repeat int
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Replay the request behind a stored finding to check whether it still reproduces",
+		Run: func(cmd *cobra.Command, args []string) {
+			if input == "" || findingID == "" {
+				log.Fatal("please specify --input and --finding")
+			}
+			if repeat < 1 {
+				log.Fatal("--repeat must be at least 1")
+			}
+
+			var err error
+			vantages, err = parseVantages(config.Vantages)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			loaded, err := loadResults(input)
+			if err != nil {
+				log.Fatal(err)
+			}
+			finding, err := findResult(loaded, findingID)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			verifyFinding(ctx, finding, repeat)
+		},
+	}
+
+	cmd.Flags().StringVar(&input, "input", "", "specify the results file (JSON or CSV) containing the finding")
+	cmd.Flags().StringVar(&findingID, "finding", "", "specify the finding fingerprint or its number in the results listing")
+	cmd.Flags().IntVar(&repeat, "repeat", 5, "specify how many times to replay the request")
+
+	return cmd
+}
+
+// replayHeader returns the headers the test of a finding sent on top of
+// the scanner's own, so that a replay asks the server the same question:
+// the method a --fuzz-methods preflight asked about, or the header of a
+// --header-reflection preflight.
+func replayHeader(finding ScanResult) http.Header {
+	header := make(http.Header)
+	if finding.FuzzMethod != "" {
+		header.Set("Access-Control-Request-Method", finding.FuzzMethod)
+	}
+	if finding.FuzzHeader != "" {
+		header.Set("Access-Control-Request-Method", http.MethodGet)
+		header.Set("Access-Control-Request-Headers", finding.FuzzHeader)
+	}
+	return header
+}
+
+// replayFinding re-sends the request behind a finding and returns the CORS
+// headers of the response.
+func replayFinding(ctx context.Context, client *http.Client, finding ScanResult) (CORSHeaders, error) {
+	resp, err := makeRequest(ctx, client, corscan.Request{Method: methodOf(finding), URL: finding.URL, Origin: finding.Origin, Header: replayHeader(finding)})
+	if err != nil {
+		return CORSHeaders{}, err
+	}
+	resp.Body.Close()
+	return corscan.ParseHeaders(resp.Header), nil
+}
+
+// verifyFinding sends the original request repeat times and reports how
+// often the server still answers with the same risky policy.
+func verifyFinding(ctx context.Context, finding ScanResult, repeat int) {
+	proxy := config.Proxy
+	for _, v := range vantages {
+		if v.Name == finding.Vantage {
+			proxy = v.Proxy
+		}
+	}
+	expected := fingerprint(finding)
+
+	fmt.Printf("Verifying %s (%s)\n", expected, finding.URL)
+	fmt.Printf("    Origin: %s\n", finding.Origin)
+	for _, risk := range assessRisks(finding) {
+		fmt.Printf("    Expected: [%s] %s\n", risk.Severity, risk.Message)
+	}
+	fmt.Println()
+
+	reproduced := 0
+	for i := 1; i <= repeat; i++ {
+		if err := waitToSend(ctx, finding.URL); err != nil {
+			fmt.Printf("[%d/%d] stopped: %v\n", i, repeat, err)
+			break
+		}
+		headers, err := replayFinding(ctx, buildHTTPClient(proxy), finding)
+		if err != nil {
+			fmt.Printf("[%d/%d] error: %v\n", i, repeat, err)
+			continue
+		}
+
+		replayed := finding
+		replayed.Headers = headers
+		if fingerprint(replayed) == expected && !headers.Empty() {
+			reproduced++
+			fmt.Printf("[%d/%d] reproduced (ACAO: %s, ACAC: %s)\n", i, repeat, headers.ACAO, headers.ACAC)
+		} else {
+			fmt.Printf("[%d/%d] not reproduced (ACAO: %s, ACAC: %s)\n", i, repeat, headers.ACAO, headers.ACAC)
+		}
+
+		if i < repeat && sleepContext(ctx, 500*time.Millisecond) != nil {
+			break
+		}
+	}
+
+	fmt.Println()
+	switch {
+	case reproduced == repeat:
+		fmt.Printf("[!] Finding still reproduces (%d/%d attempts).\n", reproduced, repeat)
+	case reproduced == 0:
+		fmt.Printf("[+] Finding no longer reproduces (0/%d attempts).\n", repeat)
+	default:
+		fmt.Printf("[!] Finding reproduces intermittently (%d/%d attempts).\n", reproduced, repeat)
+	}
+}
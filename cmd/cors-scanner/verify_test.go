@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cors-scanner/pkg/corscan"
+)
+
+// preflightServer answers preflights from any origin, with credentials,
+// but only allows a method when the preflight asks for it.
+func preflightServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions {
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", r.Header.Get("Origin"))
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+		if method := r.Header.Get("Access-Control-Request-Method"); method == "PUT" || method == "DELETE" {
+			w.Header().Set("Access-Control-Allow-Methods", method)
+		}
+	}))
+}
+
+func TestReplayFindingFuzzedMethod(t *testing.T) {
+	server := preflightServer()
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		method string
+		want   string
+	}{
+		{"PUT", "PUT", "PUT"},
+		{"DELETE", "DELETE", "DELETE"},
+		{"not allowed", "PATCH", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finding := ScanResult{
+				Result:     corscan.Result{URL: server.URL + "/", Origin: "abcdefghijkl.com", Test: testMethodFuzz, Method: http.MethodOptions},
+				FuzzMethod: tt.method,
+			}
+			headers, err := replayFinding(context.Background(), server.Client(), finding)
+			if err != nil {
+				t.Fatalf("replayFinding: %v", err)
+			}
+			if headers.ACAM != tt.want {
+				t.Errorf("replayed preflight got ACAM %q, want %q", headers.ACAM, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfirmFindingFuzzedMethod(t *testing.T) {
+	server := preflightServer()
+	defer server.Close()
+
+	finding := ScanResult{
+		Result:     corscan.Result{URL: server.URL + "/", Origin: "abcdefghijkl.com", Test: testMethodFuzz, Method: http.MethodOptions},
+		FuzzMethod: "PUT",
+	}
+	finding.Headers = CORSHeaders{ACAO: finding.Origin, ACAC: "true", ACAM: "PUT"}
+	if got := confirmFinding(context.Background(), finding); got != verifyConfirmed {
+		t.Errorf("confirmFinding = %q, want %q", got, verifyConfirmed)
+	}
+
+	replayed := finding
+	replayed.Headers = CORSHeaders{}
+	headers, err := replayFinding(context.Background(), server.Client(), replayed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed.Headers = headers
+	if fingerprint(replayed) != fingerprint(finding) {
+		t.Errorf("replayed finding has another fingerprint; the preflight asked something else")
+	}
+}
+
+func TestReplayFindingHeaderReflection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", r.Header.Get("Origin"))
+		w.Header().Set("Access-Control-Allow-Headers", r.Header.Get("Access-Control-Request-Headers"))
+	}))
+	defer server.Close()
+
+	finding := ScanResult{
+		Result:     corscan.Result{URL: server.URL + "/", Origin: "abcdefghijkl.com", Test: testHeaderReflection, Method: http.MethodOptions},
+		FuzzHeader: "x-qhtzkvmpwa",
+	}
+	headers, err := replayFinding(context.Background(), server.Client(), finding)
+	if err != nil {
+		t.Fatalf("replayFinding: %v", err)
+	}
+	finding.Headers = headers
+	if !headerEchoed(finding) {
+		t.Errorf("replayed preflight got ACAH %q, want it to echo %s", headers.ACAH, finding.FuzzHeader)
+	}
+}
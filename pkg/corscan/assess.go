@@ -0,0 +1,18 @@
+package corscan
+
+// ClassMisconfiguration classifies risks that fall outside the trust
+// classes, such as spec violations and findings of custom checks.
+const ClassMisconfiguration = "misconfiguration"
+
+// Assess sets the risks, class and severity of a result from its CORS
+// headers, together with the risks of checks made outside the library
+// (custom rules, say, or checks of the whole response). A result without
+// risks has no class and info severity.
+func (r *Result) Assess(extra ...Risk) {
+	r.Risks = append(AssessRisks(r.Test, r.Origin, r.Headers), extra...)
+	r.Class = Classify(r.Test, r.Origin, r.Headers)
+	if r.Class == "" && len(r.Risks) > 0 {
+		r.Class = ClassMisconfiguration
+	}
+	r.Severity = MaxSeverity(r.Risks)
+}
@@ -0,0 +1,54 @@
+package corscan
+
+import "testing"
+
+func TestResultAssess(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   Result
+		extra    []Risk
+		class    string
+		severity Severity
+		risks    int
+	}{
+		{
+			name:     "reflection with credentials",
+			result:   Result{Test: "reflected", Origin: "evil.com", Headers: CORSHeaders{ACAO: "evil.com", ACAC: "true"}},
+			class:    ClassReflection,
+			severity: SeverityHigh,
+			risks:    1,
+		},
+		{
+			name:     "extra risks raise the severity",
+			result:   Result{Test: "null", Origin: "null", Headers: CORSHeaders{ACAO: "null"}},
+			extra:    []Risk{{ID: "rule-admin", Severity: SeverityCritical}},
+			class:    ClassNullTrust,
+			severity: SeverityCritical,
+			risks:    2,
+		},
+		{
+			name:     "extra risks without a trust class",
+			result:   Result{Test: "existing", Origin: "api.example.com", Headers: CORSHeaders{ACAO: "api.example.com"}},
+			extra:    []Risk{{ID: "spec-acac", Severity: SeverityLow}},
+			class:    ClassMisconfiguration,
+			severity: SeverityLow,
+			risks:    1,
+		},
+		{
+			name:     "no risks",
+			result:   Result{Test: "reflected", Origin: "evil.com", Headers: CORSHeaders{ACAO: "https://app.example.com"}},
+			class:    "",
+			severity: SeverityInfo,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.result
+			result.Assess(tt.extra...)
+			if result.Class != tt.class || result.Severity != tt.severity || len(result.Risks) != tt.risks {
+				t.Errorf("Assess gave class %q, severity %s and %d risks, want %q, %s and %d",
+					result.Class, result.Severity, len(result.Risks), tt.class, tt.severity, tt.risks)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package corscan
+
+// Finding classes name the kind of trust a CORS policy grants a foreign
+// origin.
+const (
+	ClassWildcard             = "wildcard"               // any origin, Access-Control-Allow-Origin: *
+	ClassNullTrust            = "null-trust"             // the null origin of sandboxed documents
+	ClassNullTrustCredentials = "null-trust-credentials" // the null origin, with credentials
+	ClassReflection           = "reflection"             // an arbitrary origin echoed back
+	ClassSchemeDowngrade      = "scheme-downgrade"       // the target's host over the other scheme
+	ClassSubdomainWildcard    = "subdomain-wildcard"     // hosts that merely resemble the target's
+	ClassSubdomainTrust       = "subdomain-trust"        // any subdomain of the target's domain
+	ClassLocalTrust           = "local-trust"            // localhost and loopback origins
+	ClassInternalTrust        = "internal-trust"         // private-network and intranet origins
+)
+
+// Classify returns the class of the policy a test received, or "" when
+// it trusts no foreign origin. Prefix, suffix, regex-bypass and tld matches
+// are what loose patterns meant to allow every subdomain end up accepting.
+func Classify(test, origin string, headers CORSHeaders) string {
+	switch {
+	case headers.ACAO == "*":
+		return ClassWildcard
+	case headers.ACAO == "null" && headers.ACAC == "true":
+		return ClassNullTrustCredentials
+	case headers.ACAO == "null":
+		return ClassNullTrust
+	case test == "existing" || headers.ACAO == "" || headers.ACAO != origin:
+		return ""
+	case test == "scheme":
+		return ClassSchemeDowngrade
+	case test == "prefix" || test == "suffix" || test == "regex-bypass" || test == "tld":
+		return ClassSubdomainWildcard
+	case test == "subdomain":
+		return ClassSubdomainTrust
+	case test == "localhost":
+		return ClassLocalTrust
+	case test == "internal":
+		return ClassInternalTrust
+	}
+	return ClassReflection
+}
@@ -0,0 +1,42 @@
+package corscan
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name   string
+		test   string
+		origin string
+		acao   string
+		acac   string
+		want   string
+	}{
+		{"wildcard", "reflected", "evil.com", "*", "", ClassWildcard},
+		{"wildcard on the existing test", "existing", "api.example.com", "*", "", ClassWildcard},
+		{"null", "null", "null", "null", "", ClassNullTrust},
+		{"null for another origin", "reflected", "evil.com", "null", "", ClassNullTrust},
+		{"null with credentials", "null", "null", "null", "true", ClassNullTrustCredentials},
+		{"null with credentials for another origin", "reflected", "evil.com", "null", "true", ClassNullTrustCredentials},
+		{"reflection", "reflected", "evil.com", "evil.com", "", ClassReflection},
+		{"reflection with credentials", "reflected", "evil.com", "evil.com", "true", ClassReflection},
+		{"origin-list reflection", OriginListTest, "https://partner.example.net", "https://partner.example.net", "", ClassReflection},
+		{"scheme downgrade", "scheme", "http://api.example.com", "http://api.example.com", "", ClassSchemeDowngrade},
+		{"prefix", "prefix", "evilapi.example.com", "evilapi.example.com", "", ClassSubdomainWildcard},
+		{"suffix", "suffix", "api.evil.com", "api.evil.com", "", ClassSubdomainWildcard},
+		{"regex bypass", "regex-bypass", "https://api.example.com.evil.com", "https://api.example.com.evil.com", "", ClassSubdomainWildcard},
+		{"tld", "tld", "https://api.example.io", "https://api.example.io", "", ClassSubdomainWildcard},
+		{"subdomain", "subdomain", "https://x.example.com", "https://x.example.com", "", ClassSubdomainTrust},
+		{"localhost", "localhost", "http://localhost:3000", "http://localhost:3000", "", ClassLocalTrust},
+		{"internal", "internal", "http://10.0.0.1", "http://10.0.0.1", "", ClassInternalTrust},
+		{"own origin echoed", "existing", "api.example.com", "api.example.com", "", ""},
+		{"other origin allowed", "reflected", "evil.com", "https://app.example.com", "", ""},
+		{"no CORS headers", "reflected", "evil.com", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.test, tt.origin, CORSHeaders{ACAO: tt.acao, ACAC: tt.acac}); got != tt.want {
+				t.Errorf("Classify(%q, %q, ACAO %q, ACAC %q) = %q, want %q", tt.test, tt.origin, tt.acao, tt.acac, got, tt.want)
+			}
+		})
+	}
+}
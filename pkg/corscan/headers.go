@@ -0,0 +1,72 @@
+package corscan
+
+import (
+	"net/http"
+	"strings"
+)
+
+// CORSHeaders are the CORS response headers of a probe. List values are
+// stored ";"-separated so they fit a single CSV column.
+type CORSHeaders struct {
+	ACAO string `json:"acao,omitempty"` // Access-Control-Allow-Origin
+	ACAC string `json:"acac,omitempty"` // Access-Control-Allow-Credentials
+	ACAM string `json:"acam,omitempty"` // Access-Control-Allow-Methods
+	ACAH string `json:"acah,omitempty"` // Access-Control-Allow-Headers
+	ACMA string `json:"acma,omitempty"` // Access-Control-Max-Age
+	ACEH string `json:"aceh,omitempty"` // Access-Control-Expose-Headers
+
+	// ACAPN is Access-Control-Allow-Private-Network, Chrome's Private
+	// Network Access answer to a preflight carrying
+	// Access-Control-Request-Private-Network: true.
+	ACAPN string `json:"acapn,omitempty"`
+
+	// ACAOValues holds every Access-Control-Allow-Origin value when the
+	// server sent more than one, either as repeated headers or as a
+	// comma-separated list. ACAO keeps only the first.
+	ACAOValues []string `json:"acao_values,omitempty"`
+}
+
+// ParseHeaders extracts the CORS headers from a response header set.
+func ParseHeaders(header http.Header) CORSHeaders {
+	headers := CORSHeaders{}
+
+	if val := header.Get("Access-Control-Allow-Origin"); val != "" {
+		headers.ACAO = strings.ReplaceAll(val, ",", ";")
+	}
+	var origins []string
+	for _, val := range header.Values("Access-Control-Allow-Origin") {
+		for _, origin := range strings.Split(val, ",") {
+			origins = append(origins, strings.TrimSpace(origin))
+		}
+	}
+	if len(origins) > 1 {
+		headers.ACAO = origins[0]
+		headers.ACAOValues = origins
+	}
+	if val := header.Get("Access-Control-Allow-Credentials"); val != "" {
+		headers.ACAC = strings.ReplaceAll(val, ",", ";")
+	}
+	if val := header.Get("Access-Control-Allow-Methods"); val != "" {
+		headers.ACAM = strings.ReplaceAll(val, ",", ";")
+	}
+	if val := header.Get("Access-Control-Allow-Headers"); val != "" {
+		headers.ACAH = strings.ReplaceAll(val, ",", ";")
+	}
+	if val := header.Get("Access-Control-Max-Age"); val != "" {
+		headers.ACMA = strings.ReplaceAll(val, ",", ";")
+	}
+	if val := header.Get("Access-Control-Expose-Headers"); val != "" {
+		headers.ACEH = strings.ReplaceAll(val, ",", ";")
+	}
+	if val := header.Get("Access-Control-Allow-Private-Network"); val != "" {
+		headers.ACAPN = strings.ReplaceAll(val, ",", ";")
+	}
+
+	return headers
+}
+
+// Empty reports whether no CORS header was present.
+func (h CORSHeaders) Empty() bool {
+	return h.ACAO == "" && h.ACAC == "" && h.ACAM == "" &&
+		h.ACAH == "" && h.ACMA == "" && h.ACEH == "" && h.ACAPN == ""
+}
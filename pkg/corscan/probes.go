@@ -0,0 +1,296 @@
+package corscan
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Probe is one test against a target: the Origin header it sends.
+type Probe struct {
+	Test   string
+	Origin string
+}
+
+// Test is a named origin test. Origin builds the Origin header it sends
+// to a target; tests with several payloads set Origins instead, and send
+// one request per origin.
+type Test struct {
+	Name        string
+	Description string
+	Origin      func(target *url.URL) string
+	Origins     func(target *url.URL) []string
+}
+
+var registry = make(map[string]Test)
+
+// Tests lists the registered test names in the order they run.
+var Tests []string
+
+// Register adds a test to the registry. Names must be unique.
+func Register(t Test) {
+	if _, ok := registry[t.Name]; ok {
+		panic("corscan: test " + t.Name + " registered twice")
+	}
+	registry[t.Name] = t
+	Tests = append(Tests, t.Name)
+}
+
+// LookupTest returns the registered test of the given name.
+func LookupTest(name string) (Test, bool) {
+	t, ok := registry[name]
+	return t, ok
+}
+
+func init() {
+	Register(Test{
+		Name:        "existing",
+		Description: "the target's own host",
+		Origin:      func(u *url.URL) string { return u.Host },
+	})
+	Register(Test{
+		Name:        "null",
+		Description: "the null origin of sandboxed iframes and file:// pages",
+		Origin:      func(u *url.URL) string { return "null" },
+	})
+	Register(Test{
+		Name:        "reflected",
+		Description: "an unrelated random domain",
+		Origin:      func(u *url.URL) string { return randomLabel() + ".com" },
+	})
+	Register(Test{
+		Name:        "scheme",
+		Description: "the target's host over the other scheme",
+		Origin: func(u *url.URL) string {
+			if u.Scheme == "https" {
+				return "http://" + u.Host
+			}
+			return "https://" + u.Host
+		},
+	})
+	Register(Test{
+		Name:        "prefix",
+		Description: "the target's host behind a random prefix",
+		Origin:      func(u *url.URL) string { return randomLabel() + u.Host },
+	})
+	Register(Test{
+		Name:        "suffix",
+		Description: "the target's first label under a random domain",
+		Origin: func(u *url.URL) string {
+			hostname := u.Hostname()
+			domainParts := strings.Split(hostname, ".")
+			if len(domainParts) > 1 {
+				return domainParts[0] + "." + randomLabel() + "." + domainParts[len(domainParts)-1]
+			}
+			return hostname + "." + randomLabel() + ".com"
+		},
+	})
+	Register(Test{
+		Name:        "subdomain",
+		Description: "a random subdomain of the target's domain",
+		Origin: func(u *url.URL) string {
+			origin := u.Scheme + "://" + randomLabel() + "." + parentDomain(u.Hostname())
+			if port := u.Port(); port != "" {
+				origin += ":" + port
+			}
+			return origin
+		},
+	})
+	Register(Test{
+		Name:        "regex-bypass",
+		Description: "look-alikes of the target's host that slip past naive allowlist regexes",
+		Origins:     regexBypassOrigins,
+	})
+	Register(Test{
+		Name:        "tld",
+		Description: "the target's host under another top-level domain, and its domain before an attacker's",
+		Origins:     tldOrigins,
+	})
+	Register(Test{
+		Name:        "localhost",
+		Description: "loopback origins left over from development allowlists",
+		Origins: func(u *url.URL) []string {
+			return []string{"http://localhost", "http://localhost:3000", "http://127.0.0.1"}
+		},
+	})
+	Register(Test{
+		Name:        "internal",
+		Description: "private-network addresses and intranet host names",
+		Origins: func(u *url.URL) []string {
+			return []string{"http://192.168.1.1", "http://10.0.0.1", "http://172.16.0.1", "http://intranet"}
+		},
+	})
+}
+
+// tldOrigins returns the target's host with its top-level domain swapped
+// (api.example.io for api.example.com), which startsWith checks accept,
+// and its domain followed by an attacker's (example.com.<random>.net),
+// which unanchored checks accept.
+func tldOrigins(u *url.URL) []string {
+	hostname := u.Hostname()
+	port := ""
+	if u.Port() != "" {
+		port = ":" + u.Port()
+	}
+
+	labels := strings.Split(hostname, ".")
+	domain := hostname
+	var hosts []string
+	if net.ParseIP(hostname) == nil && len(labels) > 1 {
+		tld := "io"
+		if labels[len(labels)-1] == tld {
+			tld = "com"
+		}
+		hosts = append(hosts, strings.Join(labels[:len(labels)-1], ".")+"."+tld)
+		domain = strings.Join(labels[len(labels)-2:], ".")
+	}
+	hosts = append(hosts, domain+"."+randomLabel()+".net")
+
+	origins := make([]string, len(hosts))
+	for i, host := range hosts {
+		origins[i] = u.Scheme + "://" + host + port
+	}
+	return origins
+}
+
+// bypassChars are characters Safari and some parsers accept in a host
+// name. A regex that ends the target's host at any non-word character
+// takes example.com`.attacker.com for the target, although browsers send
+// it from a subdomain of attacker.com.
+var bypassChars = []string{"`", "%60", "!", "{", "}", "_", "~", "$", "&"}
+
+// regexBypassOrigins returns look-alikes of the target's host: the host
+// followed by an attacker's domain, a dot replaced as an unescaped dot in
+// a regex allows (apixexample.com for api.example.com, wwwxexample.com for
+// example.com), and the host ended by each of bypassChars.
+func regexBypassOrigins(u *url.URL) []string {
+	hostname := u.Hostname()
+	attacker := randomLabel() + ".com"
+	port := ""
+	if u.Port() != "" {
+		port = ":" + u.Port()
+	}
+
+	hosts := []string{hostname + "." + attacker, hostname + "-" + attacker}
+	if labels := strings.Split(hostname, "."); net.ParseIP(hostname) == nil && len(labels) > 1 {
+		if len(labels) > 2 {
+			hosts = append(hosts, labels[0]+"x"+strings.Join(labels[1:], "."))
+		} else {
+			hosts = append(hosts, "wwwx"+hostname)
+		}
+	}
+	for _, c := range bypassChars {
+		hosts = append(hosts, hostname+c+"."+attacker)
+	}
+
+	origins := make([]string, len(hosts))
+	for i, host := range hosts {
+		origins[i] = u.Scheme + "://" + host + port
+	}
+	return origins
+}
+
+// parentDomain drops the first label of hosts with more than two, so that
+// api.example.com yields a sibling such as <random>.example.com. IP
+// addresses are returned unchanged.
+func parentDomain(hostname string) string {
+	if net.ParseIP(hostname) != nil {
+		return hostname
+	}
+	if labels := strings.Split(hostname, "."); len(labels) > 2 {
+		return strings.Join(labels[1:], ".")
+	}
+	return hostname
+}
+
+// SelectTests returns the registered tests named in include (all of them
+// when include is empty) minus those in exclude, in registry order.
+func SelectTests(include, exclude []string) ([]string, error) {
+	for _, name := range append(append([]string(nil), include...), exclude...) {
+		if _, ok := registry[name]; !ok {
+			return nil, fmt.Errorf("unknown test %q (available: %s)", name, strings.Join(Tests, ", "))
+		}
+	}
+
+	var selected []string
+	for _, name := range Tests {
+		if (len(include) == 0 || contains(include, name)) && !contains(exclude, name) {
+			selected = append(selected, name)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no tests selected")
+	}
+	return selected, nil
+}
+
+// Probes returns the origin of every named test for a target URL, or of
+// every registered test when no names are given. The random parts change
+// on each call, so the origins never match a real site.
+func Probes(targetURL string, tests ...string) ([]Probe, error) {
+	parsedURL, err := url.Parse(targetURL)
+	if err != nil {
+		return nil, err
+	}
+	if len(tests) == 0 {
+		tests = Tests
+	}
+
+	probes := make([]Probe, 0, len(tests))
+	for _, name := range tests {
+		t, ok := registry[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown test %q", name)
+		}
+		if t.Origins != nil {
+			for _, origin := range t.Origins(parsedURL) {
+				probes = append(probes, Probe{Test: name, Origin: origin})
+			}
+			continue
+		}
+		probes = append(probes, Probe{Test: name, Origin: t.Origin(parsedURL)})
+	}
+	return probes, nil
+}
+
+// randomLabels matches the labels randomLabel generates, delimited by
+// anything but a letter.
+var randomLabels = regexp.MustCompile(`(^|[^a-z])[a-z]{12}([^a-z]|$)`)
+
+// OriginKey identifies the origin a probe sent in a way that holds across
+// scans. Tests that send a single origin are identified by their name and
+// get ""; for the other registered tests, random labels are masked so
+// that, say, the same regex-bypass character is recognised in every scan.
+// Origins of the origin list and of unregistered tests are not generated
+// here, so they are kept as they are.
+func OriginKey(test, origin string) string {
+	t, ok := registry[test]
+	if !ok {
+		return origin
+	}
+	if t.Origins == nil {
+		return ""
+	}
+	return randomLabels.ReplaceAllString(origin, "${1}*${2}")
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func randomLabel() string {
+	const charset = "abcdefghijklmnopqrstuvwxyz"
+	label := make([]byte, 12)
+	for i := range label {
+		label[i] = charset[rand.Intn(len(charset))]
+	}
+	return string(label)
+}
@@ -0,0 +1,172 @@
+package corscan
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// matchOrigin reports whether origin matches pattern, in which each *
+// stands for one random label.
+func matchOrigin(pattern, origin string) bool {
+	expr := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, `[a-z]{12}`)
+	return regexp.MustCompile("^" + expr + "$").MatchString(origin)
+}
+
+func TestProbes(t *testing.T) {
+	tests := []struct {
+		url  string
+		test string
+		want []string
+	}{
+		{"https://api.example.com:8443/v1", "existing", []string{"api.example.com:8443"}},
+		{"https://api.example.com:8443/v1", "null", []string{"null"}},
+		{"https://api.example.com:8443/v1", "reflected", []string{"*.com"}},
+		{"https://api.example.com:8443/v1", "scheme", []string{"http://api.example.com:8443"}},
+		{"http://example.com/", "scheme", []string{"https://example.com"}},
+		{"https://api.example.com:8443/v1", "prefix", []string{"*api.example.com:8443"}},
+		{"https://api.example.com:8443/v1", "suffix", []string{"api.*.com"}},
+		{"http://localhost/", "suffix", []string{"localhost.*.com"}},
+		{"https://api.example.com:8443/v1", "subdomain", []string{"https://*.example.com:8443"}},
+		{"http://example.com/", "subdomain", []string{"http://*.example.com"}},
+		{"http://10.0.0.5/", "subdomain", []string{"http://*.10.0.0.5"}},
+		{"https://api.example.com/", "regex-bypass", []string{
+			"https://api.example.com.*.com", "https://api.example.com-*.com", "https://apixexample.com",
+			"https://api.example.com`.*.com", "https://api.example.com%60.*.com", "https://api.example.com!.*.com",
+			"https://api.example.com{.*.com", "https://api.example.com}.*.com", "https://api.example.com_.*.com",
+			"https://api.example.com~.*.com", "https://api.example.com$.*.com", "https://api.example.com&.*.com",
+		}},
+		{"http://example.com:8080/", "regex-bypass", []string{
+			"http://example.com.*.com:8080", "http://example.com-*.com:8080", "http://wwwxexample.com:8080",
+			"http://example.com`.*.com:8080", "http://example.com%60.*.com:8080", "http://example.com!.*.com:8080",
+			"http://example.com{.*.com:8080", "http://example.com}.*.com:8080", "http://example.com_.*.com:8080",
+			"http://example.com~.*.com:8080", "http://example.com$.*.com:8080", "http://example.com&.*.com:8080",
+		}},
+		{"https://api.example.com:8443/v1", "tld", []string{"https://api.example.io:8443", "https://example.com.*.net:8443"}},
+		{"https://example.io/", "tld", []string{"https://example.com", "https://example.io.*.net"}},
+		{"http://10.0.0.5/", "tld", []string{"http://10.0.0.5.*.net"}},
+		{"https://api.example.com/", "localhost", []string{"http://localhost", "http://localhost:3000", "http://127.0.0.1"}},
+		{"https://api.example.com/", "internal", []string{"http://192.168.1.1", "http://10.0.0.1", "http://172.16.0.1", "http://intranet"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.test+" "+tt.url, func(t *testing.T) {
+			probes, err := Probes(tt.url, tt.test)
+			if err != nil {
+				t.Fatalf("Probes: %v", err)
+			}
+			if len(probes) != len(tt.want) {
+				t.Fatalf("Probes returned %d origins %v, want %d", len(probes), probes, len(tt.want))
+			}
+			for i, p := range probes {
+				if p.Test != tt.test {
+					t.Errorf("probe %d has test %q, want %q", i, p.Test, tt.test)
+				}
+				if !matchOrigin(tt.want[i], p.Origin) {
+					t.Errorf("probe %d origin = %q, want %q", i, p.Origin, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestProbesAllTests(t *testing.T) {
+	probes, err := Probes("https://api.example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for _, p := range probes {
+		if len(order) == 0 || order[len(order)-1] != p.Test {
+			order = append(order, p.Test)
+		}
+	}
+	if strings.Join(order, ",") != strings.Join(Tests, ",") {
+		t.Errorf("Probes ran tests %v, want %v", order, Tests)
+	}
+}
+
+func TestProbesErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		url   string
+		tests []string
+	}{
+		{"unknown test", "https://api.example.com/", []string{"reflected", "nosuchtest"}},
+		{"invalid URL", "https://api.example.com/%zz", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if probes, err := Probes(tt.url, tt.tests...); err == nil {
+				t.Errorf("Probes(%q, %v) = %v, want an error", tt.url, tt.tests, probes)
+			}
+		})
+	}
+}
+
+func TestSelectTests(t *testing.T) {
+	tests := []struct {
+		name             string
+		include, exclude []string
+		want             string
+		wantErr          bool
+	}{
+		{name: "all", want: strings.Join(Tests, ",")},
+		{name: "include in registry order", include: []string{"suffix", "null"}, want: "null,suffix"},
+		{name: "exclude", include: []string{"null", "reflected", "scheme"}, exclude: []string{"reflected"}, want: "null,scheme"},
+		{name: "unknown include", include: []string{"nosuchtest"}, wantErr: true},
+		{name: "unknown exclude", exclude: []string{"nosuchtest"}, wantErr: true},
+		{name: "nothing left", include: []string{"null"}, exclude: []string{"null"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectTests(tt.include, tt.exclude)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("SelectTests = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SelectTests: %v", err)
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("SelectTests = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOriginKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		test   string
+		origin string
+		want   string
+	}{
+		{"single-origin test", "reflected", "abcdefghijkl.com", ""},
+		{"null", "null", "null", ""},
+		{"regex-bypass label masked", "regex-bypass", "https://api.example.com`.abcdefghijkl.com", "https://api.example.com`.*.com"},
+		{"tld label masked", "tld", "https://example.com.abcdefghijkl.net", "https://example.com.*.net"},
+		{"fixed origins kept", "localhost", "http://localhost:3000", "http://localhost:3000"},
+		{"shorter labels kept", "regex-bypass", "https://apixexample.com", "https://apixexample.com"},
+		{"origin-list kept", OriginListTest, "https://partner.example.net", "https://partner.example.net"},
+		{"origin-list labels kept", OriginListTest, "https://partnerstore.example.net", "https://partnerstore.example.net"},
+		{"unregistered test kept", "custom", "https://abcdefghijkl.example.net", "https://abcdefghijkl.example.net"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OriginKey(tt.test, tt.origin); got != tt.want {
+				t.Errorf("OriginKey(%q, %q) = %q, want %q", tt.test, tt.origin, got, tt.want)
+			}
+		})
+	}
+
+	// Two scans send different random labels for the same payload.
+	first, _ := Probes("https://api.example.com/", "regex-bypass")
+	second, _ := Probes("https://api.example.com/", "regex-bypass")
+	for i := range first {
+		if OriginKey("regex-bypass", first[i].Origin) != OriginKey("regex-bypass", second[i].Origin) {
+			t.Errorf("OriginKey differs between scans for %q and %q", first[i].Origin, second[i].Origin)
+		}
+	}
+}
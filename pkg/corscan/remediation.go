@@ -0,0 +1,159 @@
+package corscan
+
+import "strings"
+
+// Remediation tells developers how to fix the risks of a result, with a
+// configuration example when the stack serving it is recognised.
+type Remediation struct {
+	Advice  []string `json:"advice"`
+	Stack   string   `json:"stack,omitempty"`
+	Example string   `json:"example,omitempty"`
+}
+
+// riskAdvice is the fix for each risk class. Spec violations share one
+// entry under "spec-".
+var riskAdvice = map[string]string{
+	"wildcard-origin":               "Replace `*` with an explicit list of trusted origins unless the resource is public and never contains user-specific data.",
+	"wildcard-credentials":          "Never combine `*` with credentials. Return only origins from an explicit allowlist, together with `Vary: Origin`.",
+	"null-origin":                   "Remove `null` from the allowed origins. Sandboxed iframes, data: URLs and local files all send it, so any attacker can obtain it.",
+	"null-origin-credentials":       "Remove `null` from the allowed origins. Sandboxed iframes, data: URLs and local files all send it, so any attacker can obtain it.",
+	"origin-reflection":             "Stop echoing the Origin header. Compare it with an exact allowlist of trusted origins (scheme, host and port; no substring, prefix or suffix matching) and return it only on a match, with `Vary: Origin`.",
+	"origin-reflection-credentials": "Stop echoing the Origin header. Compare it with an exact allowlist of trusted origins (scheme, host and port; no substring, prefix or suffix matching) and return it only on a match, with `Vary: Origin`.",
+	"subdomain-trust":               "Trust named subdomains instead of any host under the domain, so XSS or a takeover on one forgotten subdomain cannot read this origin's responses.",
+	"subdomain-trust-credentials":   "Trust named subdomains instead of any host under the domain, so XSS or a takeover on one forgotten subdomain cannot read this origin's responses.",
+	"local-origin":                  "Remove localhost and loopback origins from the production allowlist; keep development origins in development configuration.",
+	"local-origin-credentials":      "Remove localhost and loopback origins from the production allowlist; keep development origins in development configuration.",
+	"internal-origin":               "Do not trust private-network addresses or intranet host names by pattern; list the internal origins that need access, ideally on an internal-only deployment.",
+	"internal-origin-credentials":   "Do not trust private-network addresses or intranet host names by pattern; list the internal origins that need access, ideally on an internal-only deployment.",
+	"missing-vary-origin":           "Send `Vary: Origin` with every response whose CORS headers depend on the Origin, or mark such responses `Cache-Control: private` or `no-store`, so caches never serve one origin's policy to another.",
+	"reflection-unsanitized":        "Parse and validate the Origin before using it; a value with special characters should never reach a response header.",
+	"multiple-acao":                 "Send exactly one Access-Control-Allow-Origin value, and let only one layer (application, proxy or CDN) set CORS headers.",
+	"dangerous-methods":             "List only the methods cross-origin callers need in Access-Control-Allow-Methods; state-changing methods should not be open to credentialed foreign origins.",
+	"header-reflection":             "Check Access-Control-Request-Headers against an allowlist of the headers cross-origin callers need instead of copying it into Access-Control-Allow-Headers.",
+	"header-reflection-credentials": "Check Access-Control-Request-Headers against an allowlist of the headers cross-origin callers need instead of copying it into Access-Control-Allow-Headers.",
+	"private-network-access":        "Answer Access-Control-Request-Private-Network only for the exact public origins that must reach this internal service, and omit Access-Control-Allow-Private-Network otherwise.",
+	"wildcard-expose-headers":       "List the response headers scripts need in Access-Control-Expose-Headers instead of `*`.",
+	"excessive-max-age":             "Lower Access-Control-Max-Age (600 seconds is plenty) so policy fixes reach browsers quickly.",
+	"spec-":                         "Correct the header value to the syntax of the Fetch standard; browsers and proxies handle invalid values inconsistently.",
+}
+
+// stackExamples are allowlist-based CORS configurations per stack.
+var stackExamples = map[string]string{
+	"nginx": `map $http_origin $cors_origin {
+    default "";
+    "https://app.example.com" $http_origin;
+}
+
+server {
+    add_header Access-Control-Allow-Origin $cors_origin always;
+    add_header Access-Control-Allow-Credentials true always;
+    add_header Vary Origin always;
+}`,
+	"Apache": `SetEnvIf Origin "^https://app\.example\.com$" CORS_ORIGIN=$0
+Header always set Access-Control-Allow-Origin "%{CORS_ORIGIN}e" env=CORS_ORIGIN
+Header always set Access-Control-Allow-Credentials "true" env=CORS_ORIGIN
+Header always merge Vary Origin`,
+	"Express": `const cors = require("cors");
+
+app.use(cors({
+  origin: ["https://app.example.com"],
+  credentials: true,
+}));`,
+	"Kong": `plugins:
+- name: cors
+  config:
+    origins: ["https://app.example.com"]
+    credentials: true`,
+	"Envoy": `cors:
+  allow_origin_string_match:
+  - exact: https://app.example.com
+  allow_credentials: true`,
+	"IIS": `<system.webServer>
+  <cors enabled="true" failUnlistedOrigins="true">
+    <add origin="https://app.example.com" allowCredentials="true" />
+  </cors>
+</system.webServer>`,
+	"Spring": `@Bean
+WebMvcConfigurer corsConfigurer() {
+    return new WebMvcConfigurer() {
+        @Override
+        public void addCorsMappings(CorsRegistry registry) {
+            registry.addMapping("/**")
+                .allowedOrigins("https://app.example.com")
+                .allowCredentials(true);
+        }
+    };
+}`,
+	"PHP": `$allowed = ['https://app.example.com'];
+$origin = $_SERVER['HTTP_ORIGIN'] ?? '';
+if (in_array($origin, $allowed, true)) {
+    header('Access-Control-Allow-Origin: ' . $origin);
+    header('Access-Control-Allow-Credentials: true');
+}
+header('Vary: Origin');`,
+}
+
+// stackMarkers map products in Server, X-Powered-By and edge names to an
+// example, checked in order so the application framework wins over the
+// web server in front of it.
+var stackMarkers = []struct {
+	marker string
+	stack  string
+}{
+	{"express", "Express"},
+	{"php", "PHP"},
+	{"asp.net", "IIS"},
+	{"microsoft-iis", "IIS"},
+	{"apache-coyote", "Spring"},
+	{"tomcat", "Spring"},
+	{"kong", "Kong"},
+	{"envoy", "Envoy"},
+	{"nginx", "nginx"},
+	{"apache", "Apache"},
+}
+
+// exampleStack returns the stack with an example among the products a
+// response names, or "".
+func exampleStack(products []string) string {
+	names := strings.ToLower(strings.Join(products, " "))
+	for _, m := range stackMarkers {
+		if strings.Contains(names, m.marker) {
+			return m.stack
+		}
+	}
+	return ""
+}
+
+// Advice returns the fix for a risk, or "" for risks without advice.
+func Advice(riskID string) string {
+	if text, ok := riskAdvice[riskID]; ok {
+		return text
+	}
+	if strings.HasPrefix(riskID, "spec-") {
+		return riskAdvice["spec-"]
+	}
+	return ""
+}
+
+// RemediationFor returns the fixes for a set of risks, or nil when none of
+// them has advice. Products are the server, framework and edge names a
+// response revealed (Server, X-Powered-By and the like); a recognised one
+// adds a configuration example.
+func RemediationFor(risks []Risk, products ...string) *Remediation {
+	var advice []string
+	for _, risk := range risks {
+		if text := Advice(risk.ID); text != "" && !contains(advice, text) {
+			advice = append(advice, text)
+		}
+	}
+	if len(advice) == 0 {
+		return nil
+	}
+
+	remediation := &Remediation{Advice: advice}
+	if stack := exampleStack(products); stack != "" {
+		remediation.Stack = stack
+		remediation.Example = stackExamples[stack]
+	}
+	return remediation
+}
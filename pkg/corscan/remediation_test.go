@@ -0,0 +1,46 @@
+package corscan
+
+import "testing"
+
+func TestRemediationFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		risks    []string
+		products []string
+		advice   int
+		stack    string
+	}{
+		{name: "one risk", risks: []string{"wildcard-origin"}, advice: 1},
+		{name: "shared advice given once", risks: []string{"origin-reflection", "origin-reflection-credentials"}, advice: 1},
+		{name: "spec violations", risks: []string{"spec-acac", "spec-acam"}, advice: 1},
+		{name: "no advice", risks: []string{"rule-admin"}},
+		{name: "stack example", risks: []string{"null-origin"}, products: []string{"", "nginx/1.25.3"}, advice: 1, stack: "nginx"},
+		{name: "framework before web server", risks: []string{"null-origin"}, products: []string{"Express", "nginx"}, advice: 1, stack: "Express"},
+		{name: "unknown stack", risks: []string{"null-origin"}, products: []string{"Caddy"}, advice: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var risks []Risk
+			for _, id := range tt.risks {
+				risks = append(risks, Risk{ID: id})
+			}
+			remediation := RemediationFor(risks, tt.products...)
+			if tt.advice == 0 {
+				if remediation != nil {
+					t.Errorf("RemediationFor = %+v, want nil", remediation)
+				}
+				return
+			}
+			if remediation == nil {
+				t.Fatalf("RemediationFor = nil, want %d pieces of advice", tt.advice)
+			}
+			if len(remediation.Advice) != tt.advice || remediation.Stack != tt.stack {
+				t.Errorf("RemediationFor gave %d pieces of advice for stack %q, want %d for %q",
+					len(remediation.Advice), remediation.Stack, tt.advice, tt.stack)
+			}
+			if (remediation.Example != "") != (tt.stack != "") {
+				t.Errorf("example %q does not match stack %q", remediation.Example, tt.stack)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package corscan
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityLow:
+		return "low"
+	case SeverityMedium:
+		return "medium"
+	case SeverityHigh:
+		return "high"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "info"
+	}
+}
+
+// MarshalText stores severities by name in result files.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	severity, ok := ParseSeverity(string(text))
+	if !ok {
+		return fmt.Errorf("unknown severity %q", text)
+	}
+	*s = severity
+	return nil
+}
+
+// Label returns the severity name capitalised for reports.
+func (s Severity) Label() string {
+	name := s.String()
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// ParseSeverity reads a severity name, ignoring case.
+func ParseSeverity(name string) (Severity, bool) {
+	for s := SeverityInfo; s <= SeverityCritical; s++ {
+		if strings.EqualFold(name, s.String()) {
+			return s, true
+		}
+	}
+	return SeverityInfo, false
+}
+
+// maxAgeThreshold is the Access-Control-Max-Age (in seconds) above which
+// preflight caching is considered excessive.
+const maxAgeThreshold = 24 * 60 * 60
+
+// Risk is a single security implication derived from a scan result.
+type Risk struct {
+	ID       string   `json:"id"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// AssessRisks returns the risks of the CORS headers a test received for
+// the origin it sent.
+func AssessRisks(test, origin string, headers CORSHeaders) []Risk {
+	var risks []Risk
+	credentials := headers.ACAC == "true"
+
+	if headers.ACAO == "*" {
+		risks = append(risks, Risk{"wildcard-origin", SeverityLow, "Wildcard origin allows any domain!"})
+		if credentials {
+			risks = append(risks, Risk{"wildcard-credentials", SeverityCritical, "Wildcard origin with credentials - major security flaw!"})
+		}
+	}
+	// Sandboxed iframes send Origin: null, but without credentials they
+	// can only read what an anonymous request could anyway.
+	if headers.ACAO == "null" {
+		if credentials {
+			risks = append(risks, Risk{"null-origin-credentials", SeverityHigh, "Null origin accepted with credentials - sandboxed iframes can read authenticated responses!"})
+		} else {
+			risks = append(risks, Risk{"null-origin", SeverityLow, "Null origin accepted - potential security risk!"})
+		}
+	}
+	// The existing policy test sends the target's own host, so echoing it
+	// back is expected behaviour rather than reflection.
+	reflected := test != "existing" && headers.ACAO != "" && headers.ACAO == origin && headers.ACAO != "null"
+	switch {
+	case !reflected:
+	// A trusted subdomain is only exploitable through XSS or a takeover on
+	// one of them, so it ranks below reflection of any origin.
+	case test == "subdomain":
+		if credentials {
+			risks = append(risks, Risk{"subdomain-trust-credentials", SeverityMedium, "Any subdomain trusted with credentials - XSS or takeover on one subdomain can read authenticated responses"})
+		} else {
+			risks = append(risks, Risk{"subdomain-trust", SeverityLow, "Any subdomain trusted"})
+		}
+	// Local origins need a compromised or malicious app on the victim's
+	// own machine, such as a dev server or an installed tool.
+	case test == "localhost":
+		if credentials {
+			risks = append(risks, Risk{"local-origin-credentials", SeverityMedium, "Localhost origin trusted with credentials - any local web app on a victim's machine can read authenticated responses"})
+		} else {
+			risks = append(risks, Risk{"local-origin", SeverityLow, "Localhost origin trusted - leftover development allowlist"})
+		}
+	// Internal origins need a foothold on the private network: a
+	// compromised intranet host, or an SSRF that serves attacker content.
+	case test == "internal":
+		if credentials {
+			risks = append(risks, Risk{"internal-origin-credentials", SeverityMedium, "Internal origin trusted with credentials - a compromised intranet host can read authenticated responses"})
+		} else {
+			risks = append(risks, Risk{"internal-origin", SeverityLow, "Internal origin trusted"})
+		}
+	default:
+		if credentials {
+			risks = append(risks, Risk{"origin-reflection-credentials", SeverityHigh, "Origin reflection with credentials - attacker origin can read authenticated responses!"})
+		} else {
+			risks = append(risks, Risk{"origin-reflection", SeverityMedium, "Origin reflection detected"})
+		}
+	}
+
+	// The checks below only matter once a foreign origin is allowed.
+	foreignAllowed := len(risks) > 0
+
+	if len(headers.ACAOValues) > 1 {
+		risks = append(risks, Risk{"multiple-acao", SeverityLow,
+			fmt.Sprintf("Multiple Access-Control-Allow-Origin values (%s) - invalid, browsers reject it but some clients use the first", strings.Join(headers.ACAOValues, " | "))})
+	}
+
+	// State-changing methods only matter when a foreign origin is allowed
+	// to send credentialed requests.
+	if credentials && foreignAllowed {
+		if methods := DangerousMethods(headers.ACAM); len(methods) > 0 {
+			risks = append(risks, Risk{"dangerous-methods", SeverityMedium,
+				fmt.Sprintf("State-changing methods allowed cross-origin with credentials: %s", strings.Join(methods, ", "))})
+		}
+	}
+
+	// Private Network Access lets a public page reach a service on the
+	// victim's private network; granting it to a foreign origin opens the
+	// intranet to that origin.
+	if foreignAllowed && strings.EqualFold(strings.TrimSpace(headers.ACAPN), "true") {
+		risks = append(risks, Risk{"private-network-access", SeverityMedium, "Private network access granted to a foreign origin - public sites can reach this internal service from a victim's browser"})
+	}
+
+	if ContainsToken(headers.ACEH, "*") {
+		if credentials && foreignAllowed {
+			risks = append(risks, Risk{"wildcard-expose-headers", SeverityMedium, "Expose-Headers wildcard with credentials - all response headers readable by an allowed foreign origin"})
+		} else {
+			risks = append(risks, Risk{"wildcard-expose-headers", SeverityLow, "Expose-Headers wildcard exposes every response header"})
+		}
+	}
+
+	// A long preflight cache keeps a risky policy pinned in browsers after
+	// the server is fixed, so it only matters alongside another risk.
+	if foreignAllowed {
+		if maxAge, err := strconv.Atoi(strings.TrimSpace(headers.ACMA)); err == nil && maxAge > maxAgeThreshold {
+			risks = append(risks, Risk{"excessive-max-age", SeverityLow,
+				fmt.Sprintf("Preflight cached for %s (Access-Control-Max-Age: %d) - extends the exploitation window", time.Duration(maxAge)*time.Second, maxAge)})
+		}
+	}
+
+	return risks
+}
+
+// riskyMethods are methods that change state or are otherwise unusual to
+// allow cross-origin.
+var riskyMethods = []string{"PUT", "DELETE", "PATCH", "TRACE", "TRACK", "CONNECT"}
+
+// DangerousMethods returns the risky methods listed in an
+// Access-Control-Allow-Methods value (as stored, ";"-separated).
+func DangerousMethods(acam string) []string {
+	allowed := make(map[string]bool)
+	for _, method := range strings.FieldsFunc(acam, func(r rune) bool { return r == ';' || r == ',' || r == ' ' }) {
+		allowed[strings.ToUpper(method)] = true
+	}
+
+	var found []string
+	for _, method := range riskyMethods {
+		if allowed[method] {
+			found = append(found, method)
+		}
+	}
+	return found
+}
+
+// ContainsToken reports whether a list-valued header (as stored,
+// ";"-separated) contains token.
+func ContainsToken(value, token string) bool {
+	for _, item := range strings.Split(value, ";") {
+		if strings.EqualFold(strings.TrimSpace(item), token) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxSeverity returns the highest severity among risks, or info.
+func MaxSeverity(risks []Risk) Severity {
+	max := SeverityInfo
+	for _, risk := range risks {
+		if risk.Severity > max {
+			max = risk.Severity
+		}
+	}
+	return max
+}
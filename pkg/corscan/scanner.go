@@ -0,0 +1,310 @@
+// Package corscan finds CORS misconfigurations. It sends every target a
+// set of requests with crafted Origin headers and assesses the CORS
+// headers that come back.
+//
+//	scanner := corscan.NewScanner(corscan.Config{Threads: 10})
+//	results, err := scanner.Scan(ctx, []string{"https://example.com"})
+package corscan
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// OriginListTest is the test name of results for Config.Origins.
+const OriginListTest = "origin-list"
+
+// Config controls a Scanner. The zero value is usable.
+type Config struct {
+	Threads int           // targets scanned concurrently (default 10)
+	Timeout time.Duration // per-request timeout of the default client (default 10s)
+	Header  http.Header   // sent with every request, e.g. User-Agent or Cookie
+	Tests   []string      // names of the tests to run (default all of Tests)
+
+	// Origins are sent to every URL after the tests, e.g. partner or
+	// staging domains; their results have the test OriginListTest.
+	Origins []string
+
+	// Methods returns the methods to send every test to a URL with
+	// (default GET). OPTIONS requests are sent as preflights.
+	Methods func(targetURL string) []string
+
+	// PreflightHeader is added to OPTIONS requests to make them CORS
+	// preflights (default Access-Control-Request-Method: GET).
+	PreflightHeader http.Header
+
+	// CaptureHeaders stores the complete response header set with each
+	// result.
+	CaptureHeaders bool
+
+	// Insecure makes the default client skip certificate verification.
+	Insecure bool
+
+	// Client sends the requests. The default client does not follow
+	// redirects.
+	Client *http.Client
+
+	// Vantages are the network positions every request is sent from,
+	// each with its own client. The default is Client alone.
+	Vantages []Vantage
+
+	// Send sends a request, e.g. with retries, pacing or request signing.
+	// The default builds it from Header and the Request, and sends it
+	// with the vantage's client.
+	Send func(ctx context.Context, client *http.Client, req Request) (*http.Response, error)
+
+	// OnExchange is called after every request, failed or not, before
+	// the response body is closed.
+	OnExchange func(ctx context.Context, x *Exchange)
+}
+
+// Vantage is a named network position requests are sent from, usually a
+// proxy in another region or network.
+type Vantage struct {
+	Name   string
+	Client *http.Client
+}
+
+// Request is a test request for Config.Send. Header holds the headers
+// the test itself needs, such as those of a preflight.
+type Request struct {
+	Method string
+	URL    string
+	Origin string // not sent when empty
+	Header http.Header
+}
+
+// Exchange is one test request and its outcome.
+type Exchange struct {
+	Probe    Probe
+	Vantage  Vantage
+	Request  Request
+	Response *http.Response // nil when Err is set
+	Result   Result         // valid when Err is nil
+	Err      error
+	Duration time.Duration
+}
+
+// Result is the response of a target to one test. Only responses with
+// CORS headers are reported.
+type Result struct {
+	URL             string      `json:"url"`
+	Origin          string      `json:"origin"`
+	Test            string      `json:"test,omitempty"`
+	Method          string      `json:"method,omitempty"`
+	Protocol        string      `json:"protocol,omitempty"` // HTTP version of the response
+	Status          int         `json:"status,omitempty"`   // HTTP status of the response
+	Vantage         string      `json:"vantage,omitempty"`
+	Headers         CORSHeaders `json:"headers"`
+	Class           string      `json:"class,omitempty"`
+	Severity        Severity    `json:"severity"`
+	Encoding        string      `json:"content_encoding,omitempty"`
+	ResponseHeaders http.Header `json:"response_headers,omitempty"` // only with CaptureHeaders
+	Risks           []Risk      `json:"risks,omitempty"`
+	ScannedAt       time.Time   `json:"scanned_at"`
+}
+
+// Scanner tests targets for CORS misconfigurations. It is safe for
+// concurrent use.
+type Scanner struct {
+	config Config
+	client *http.Client
+}
+
+// NewScanner returns a Scanner for config, filling in the defaults of
+// unset fields.
+func NewScanner(config Config) *Scanner {
+	if config.Threads <= 0 {
+		config.Threads = 10
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if len(config.Tests) == 0 {
+		config.Tests = Tests
+	}
+	if config.PreflightHeader == nil {
+		config.PreflightHeader = http.Header{"Access-Control-Request-Method": {http.MethodGet}}
+	}
+
+	client := config.Client
+	if client == nil {
+		client = &http.Client{
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: config.Insecure}},
+			Timeout:   config.Timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+	if len(config.Vantages) == 0 {
+		config.Vantages = []Vantage{{Client: client}}
+	}
+	return &Scanner{config: config, client: client}
+}
+
+// Vantages returns the network positions the scanner sends requests from.
+func (s *Scanner) Vantages() []Vantage {
+	return s.config.Vantages
+}
+
+// Scan runs the configured tests against every URL. Failed requests do
+// not stop the scan; their errors are joined into the returned error
+// alongside the results that were found. When ctx is cancelled, Scan
+// returns the results so far and ctx.Err().
+func (s *Scanner) Scan(ctx context.Context, urls []string) ([]Result, error) {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results []Result
+		errs    []error
+	)
+	urlChan := make(chan string)
+
+	for i := 0; i < s.config.Threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for targetURL := range urlChan {
+				found, err := s.ScanURL(ctx, targetURL)
+				mu.Lock()
+				results = append(results, found...)
+				if err != nil {
+					errs = append(errs, err)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+send:
+	for _, targetURL := range urls {
+		select {
+		case urlChan <- targetURL:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(urlChan)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return results, err
+	}
+	return results, errors.Join(errs...)
+}
+
+// ScanURL runs the configured tests against a single URL, from every
+// vantage and with every method.
+func (s *Scanner) ScanURL(ctx context.Context, targetURL string) ([]Result, error) {
+	probes, err := Probes(targetURL, s.config.Tests...)
+	if err != nil {
+		return nil, err
+	}
+	for _, origin := range s.config.Origins {
+		probes = append(probes, Probe{Test: OriginListTest, Origin: origin})
+	}
+
+	methods := []string{http.MethodGet}
+	if s.config.Methods != nil {
+		methods = s.config.Methods(targetURL)
+	}
+
+	var results []Result
+	var errs []error
+	for _, p := range probes {
+		for _, v := range s.config.Vantages {
+			for _, method := range methods {
+				if err := ctx.Err(); err != nil {
+					return results, err
+				}
+				result, err := s.probe(ctx, v, targetURL, method, p)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s test against %s: %w", p.Test, targetURL, err))
+					continue
+				}
+				if !result.Headers.Empty() {
+					results = append(results, result)
+				}
+			}
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+func (s *Scanner) probe(ctx context.Context, v Vantage, targetURL, method string, p Probe) (Result, error) {
+	req := Request{Method: method, URL: targetURL, Origin: p.Origin}
+	if method == http.MethodOptions {
+		req.Header = s.config.PreflightHeader
+	}
+	return s.Do(ctx, v, p.Test, req)
+}
+
+// Do sends a request of the caller's own from v and assesses it as the
+// named test. It is sent and reported to OnExchange like the requests of
+// ScanURL, so callers can add tests, such as preflights asking about
+// other methods, without bypassing Send or OnExchange.
+func (s *Scanner) Do(ctx context.Context, v Vantage, test string, req Request) (Result, error) {
+	p := Probe{Test: test, Origin: req.Origin}
+	x := &Exchange{Probe: p, Vantage: v, Request: req}
+	start := time.Now()
+	resp, err := s.send(ctx, v.Client, req)
+	x.Duration = time.Since(start)
+	if err != nil {
+		x.Err = err
+		if s.config.OnExchange != nil {
+			s.config.OnExchange(ctx, x)
+		}
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+
+	headers := ParseHeaders(resp.Header)
+	x.Response = resp
+	x.Result = Result{
+		URL:       req.URL,
+		Origin:    p.Origin,
+		Test:      p.Test,
+		Method:    req.Method,
+		Protocol:  resp.Proto,
+		Status:    resp.StatusCode,
+		Vantage:   v.Name,
+		Headers:   headers,
+		Encoding:  resp.Header.Get("Content-Encoding"),
+		ScannedAt: time.Now().UTC(),
+	}
+	x.Result.Assess()
+	if s.config.CaptureHeaders {
+		x.Result.ResponseHeaders = resp.Header.Clone()
+	}
+	if s.config.OnExchange != nil {
+		s.config.OnExchange(ctx, x)
+	}
+	return x.Result, nil
+}
+
+func (s *Scanner) send(ctx context.Context, client *http.Client, r Request) (*http.Response, error) {
+	if s.config.Send != nil {
+		return s.config.Send(ctx, client, r)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.Method, r.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range s.config.Header {
+		req.Header[name] = values
+	}
+	for name, values := range r.Header {
+		req.Header[name] = values
+	}
+	if r.Origin != "" {
+		req.Header.Set("Origin", r.Origin)
+	}
+	return client.Do(req)
+}